@@ -0,0 +1,59 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+// Alias adds each of long as an alias of the long name of o.  If o has already
+// been added to a Set then the aliases are also added to that Set.  It is an
+// error to use an alias that is already an option name.
+func (o *option) Alias(long ...string) Option {
+	for _, name := range long {
+		o.longAliases = append(o.longAliases, name)
+		if o.set != nil && !o.positional {
+			o.set.addLong(o, name)
+		}
+	}
+	return o
+}
+
+// ShortAlias adds each of short as an alias of the short name of o.  If o has
+// already been added to a Set then the aliases are also added to that Set.  It
+// is an error to use an alias that is already an option name.
+func (o *option) ShortAlias(short ...rune) Option {
+	for _, c := range short {
+		o.shortAliases = append(o.shortAliases, c)
+		if o.set != nil && !o.positional {
+			o.set.addShort(o, c)
+		}
+	}
+	return o
+}
+
+// longNames returns the long name of o, if any, followed by its aliases.
+func (o *option) longNames() []string {
+	if o.long == "" {
+		return o.longAliases
+	}
+	return append([]string{o.long}, o.longAliases...)
+}
+
+// shortNames returns the short name of o, if any, followed by its aliases.
+func (o *option) shortNames() []rune {
+	if o.short == 0 {
+		return o.shortAliases
+	}
+	return append([]rune{o.short}, o.shortAliases...)
+}
+
+// SetShowAliases calls SetShowAliases on the command line options.
+func SetShowAliases(show bool) {
+	CommandLine.SetShowAliases(show)
+}
+
+// SetShowAliases sets whether PrintOptions displays the aliases of the options
+// in s (see Option.Alias) along with their names.  By default only the names
+// are displayed.
+func (s *Set) SetShowAliases(show bool) {
+	s.showAliases = show
+}
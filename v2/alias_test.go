@@ -0,0 +1,143 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	for _, tt := range []struct {
+		where  string
+		args   []string
+		abbrev bool
+		color  bool
+		dryRun string
+		name   string
+		err    string
+	}{
+		{loc(), []string{"prog", "--color"}, false, true, "", "--color", ""},
+		{loc(), []string{"prog", "--colour"}, false, true, "", "--colour", ""},
+		{loc(), []string{"prog", "-C"}, false, true, "", "-C", ""},
+		{loc(), []string{"prog", "-c"}, false, true, "", "-c", ""},
+		{loc(), []string{"prog", "--colour", "--no-colour"}, false, false, "", "--no-colour", ""},
+		{loc(), []string{"prog", "--colo"}, true, true, "", "--color", ""},
+		{loc(), []string{"prog", "--colou"}, true, true, "", "--colour", ""},
+		{loc(), []string{"prog", "--dryrun=x"}, false, false, "x", "--dryrun", ""},
+		{loc(), []string{"prog", "--dry=x"}, true, false, "x", "--dry-run", ""},
+		{loc(), []string{"prog", "--dryr=x"}, false, false, "", "", "unknown option: --dryr"},
+	} {
+		reset()
+		var color bool
+		var dryRun string
+		FlagLong(&color, "color", 'c', "use color").Alias("colour").ShortAlias('C').Negatable()
+		FlagLong(&dryRun, "dry-run", 'n', "dry run mode").Alias("dryrun")
+		SetAbbreviations(tt.abbrev)
+		var name string
+		err := CommandLine.Getopt(tt.args, func(o Option) bool {
+			name = o.Name()
+			return true
+		})
+		var es string
+		if err != nil {
+			es = err.Error()
+		}
+		if es != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.where, es, tt.err)
+			continue
+		}
+		if color != tt.color || dryRun != tt.dryRun {
+			t.Errorf("%s: got %v, %q, want %v, %q", tt.where, color, dryRun, tt.color, tt.dryRun)
+		}
+		if name != tt.name {
+			t.Errorf("%s: got name %q, want %q", tt.where, name, tt.name)
+		}
+	}
+}
+
+func TestAliasUsage(t *testing.T) {
+	HelpColumn = 20
+	reset()
+	var color bool
+	var dryRun string
+	FlagLong(&color, "color", 'c', "use color").Alias("colour").ShortAlias('C').Negatable()
+	FlagLong(&dryRun, "dry-run", 'n', "dry run mode").Alias("dryrun")
+	var b bytes.Buffer
+	CommandLine.PrintOptions(&b)
+	want := ` -c, --[no-]color  use color
+ -n, --dry-run=value
+                   dry run mode
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	SetShowAliases(true)
+	b.Reset()
+	CommandLine.PrintOptions(&b)
+	want = ` -c, -C, --[no-]color, --colour
+       use color
+ -n, --dry-run, --dryrun=value
+       dry run mode
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := CommandLine.Complete([]string{"--col"}); badSlice(got, []string{"--color", "--colour"}) {
+		t.Errorf("got completions %q", got)
+	}
+}
+
+func TestAliasDup(t *testing.T) {
+	defer func(fn func(int)) { exit = fn }(exit)
+	defer func(w io.Writer) { stderr = w }(stderr)
+	var errbuf bytes.Buffer
+	stderr = &errbuf
+	exit = func(int) {}
+
+	reset()
+	var color bool
+	var dryRun string
+	FlagLong(&color, "color", 'c', "use color").Alias("colour").ShortAlias('C').Negatable()
+	FlagLong(&dryRun, "dry-run", 'n', "dry run mode").Alias("dryrun")
+	var v bool
+	FlagLong(&v, "verbose", 'v').Alias("dryrun")
+	if !strings.Contains(errbuf.String(), "--dryrun already declared") {
+		t.Errorf("got %q, want --dryrun already declared", errbuf.String())
+	}
+	errbuf.Reset()
+	FlagLong(&v, "quiet", 0).ShortAlias('C')
+	if !strings.Contains(errbuf.String(), "-C already declared") {
+		t.Errorf("got %q, want -C already declared", errbuf.String())
+	}
+}
+
+func TestAliasJSON(t *testing.T) {
+	reset()
+	var color bool
+	var dryRun string
+	FlagLong(&color, "color", 'c', "use color").Alias("colour").ShortAlias('C').Negatable()
+	FlagLong(&dryRun, "dry-run", 'n', "dry run mode").Alias("dryrun")
+	var b bytes.Buffer
+	if err := CommandLine.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Getopt([]string{"prog", "-C", "--dryrun=x"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Lookup("color").String(); got != "true" {
+		t.Errorf("color: got %q, want true", got)
+	}
+	if got := s.Lookup("dry-run").String(); got != "x" {
+		t.Errorf("dry-run: got %q, want x", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetCollectErrors calls SetCollectErrors on the command line options.
+func SetCollectErrors(collect bool) {
+	CommandLine.SetCollectErrors(collect)
+}
+
+// SetCollectErrors sets whether Getopt continues parsing after an error.  When
+// collecting, an unknown option is skipped along with its possible value: the
+// rest of its argument, or, if that is empty, the next argument unless it
+// starts with a dash.  An option with a missing or bad value is skipped.  Only
+// an unreadable response file stops parsing.  Getopt then checks the options,
+// as it does when there are no errors, and returns all the errors as Errors.
+// Parse displays each of them.
+func (s *Set) SetCollectErrors(collect bool) {
+	s.collectErrors = collect
+}
+
+// Errors are the errors returned by Getopt when collecting errors (see
+// SetCollectErrors), in the order they were encountered.
+type Errors []*Error
+
+// Error returns the messages of the errors in e, one per line.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for x, err := range e {
+		msgs[x] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the errors in e matches target, as reported by
+// errors.Is.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in e that matches target, as reported by
+// errors.As, and if one is found, sets target to that error and returns true.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// asError returns err as an *Error.
+func asError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{ErrorCode: Invalid, Err: err}
+}
+
+// printError writes err, or each error in err if it is Errors, to the output
+// of s along with any suggested options.
+func (s *Set) printError(err error) {
+	if errs, ok := err.(Errors); ok {
+		for _, err := range errs {
+			s.printError(err)
+		}
+		return
+	}
+	fmt.Fprintln(s.writer(), err)
+	if e, ok := err.(*Error); ok && len(e.Suggestions) > 0 {
+		fmt.Fprintf(s.writer(), "did you mean %s?\n", strings.Join(e.Suggestions, " or "))
+	}
+}
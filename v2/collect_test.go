@@ -0,0 +1,184 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestCollectErrors(t *testing.T) {
+	for _, tt := range []struct {
+		where   string
+		in      []string
+		count   int
+		verbose bool
+		args    []string
+		codes   []ErrorCode
+		errs    []string
+	}{
+		{
+			where:   loc(),
+			in:      []string{"prog", "-n", "x", "-v", "file"},
+			verbose: true,
+			args:    []string{"file"},
+		},
+		{
+			where:   loc(),
+			in:      []string{"prog", "--verbsoe", "--count=x", "-zq", "-v", "-n", "x", "file"},
+			verbose: true,
+			args:    []string{"file"},
+			codes:   []ErrorCode{UnknownOption, Invalid, UnknownOption},
+			errs: []string{
+				"unknown option: --verbsoe",
+				"",
+				"unknown option: -z",
+			},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--typo", "x", "-z", "y", "--count=bad", "-n", "x", "file"},
+			args:  []string{"file"},
+			codes: []ErrorCode{UnknownOption, UnknownOption, Invalid},
+			errs: []string{
+				"unknown option: --typo",
+				"unknown option: -z",
+				"not a valid number: bad",
+			},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--bogus=1", "-c", "2", "--verbose=yes", "-c"},
+			count: 2,
+			codes: []ErrorCode{UnknownOption, Invalid, MissingParameter, MissingMandatory},
+			errs: []string{
+				"unknown option: --bogus",
+				`invalid value for bool --verbose: "yes"`,
+				"missing parameter for -c",
+				"option -n is mandatory",
+			},
+		},
+	} {
+		reset()
+		SetCollectErrors(true)
+		var count int
+		var verbose bool
+		var name string
+		FlagLong(&count, "count", 'c', "the count")
+		FlagLong(&verbose, "verbose", 'v', "be verbose")
+		FlagLong(&name, "name", 'n', "the name").Mandatory()
+		err := CommandLine.Getopt(tt.in, nil)
+		if count != tt.count || verbose != tt.verbose {
+			t.Errorf("%s: got %d, %v, want %d, %v", tt.where, count, verbose, tt.count, tt.verbose)
+		}
+		if badSlice(Args(), tt.args) {
+			t.Errorf("%s: got args %q, want %q", tt.where, Args(), tt.args)
+		}
+		if len(tt.codes) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.where, err)
+			}
+			continue
+		}
+		errs, ok := err.(Errors)
+		if !ok {
+			t.Errorf("%s: got error %v (%T), want Errors", tt.where, err, err)
+			continue
+		}
+		if got := CommandLine.State(); got != Failure {
+			t.Errorf("%s: got state %v, want %v", tt.where, got, Failure)
+		}
+		if len(errs) != len(tt.codes) {
+			t.Errorf("%s: got %d errors, want %d: %v", tt.where, len(errs), len(tt.codes), errs)
+			continue
+		}
+		for x, e := range errs {
+			if e.ErrorCode != tt.codes[x] {
+				t.Errorf("%s: error %d: got code %v, want %v", tt.where, x, e.ErrorCode, tt.codes[x])
+			}
+			if tt.errs[x] != "" && e.Error() != tt.errs[x] {
+				t.Errorf("%s: error %d: got %q, want %q", tt.where, x, e.Error(), tt.errs[x])
+			}
+		}
+	}
+}
+
+func TestCollectErrorsResponseFile(t *testing.T) {
+	reset()
+	SetCollectErrors(true)
+	SetResponseFiles(true)
+	var verbose bool
+	var name string
+	FlagLong(&verbose, "verbose", 'v', "be verbose")
+	FlagLong(&name, "name", 'n', "the name").Mandatory()
+	err := CommandLine.Getopt([]string{"prog", "--bogus", "@/does/not/exist", "-v"}, nil)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("got error %v (%T), want Errors", err, err)
+	}
+	// A bad response file is reported before any option is parsed.
+	if len(errs) != 2 || errs[0].ErrorCode != BadResponseFile || errs[1].ErrorCode != MissingMandatory {
+		t.Errorf("got errors %v", errs)
+	}
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	reset()
+	SetCollectErrors(true)
+	var count int
+	var name string
+	FlagLong(&count, "count", 'c', "the count")
+	FlagLong(&name, "name", 'n', "the name")
+	err := CommandLine.Getopt([]string{"prog", "-c", "x", "-n", "y"}, nil)
+	if err == nil {
+		t.Fatal("did not get an error")
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("errors.As did not find an *Error in %v", err)
+	}
+	if e.ErrorCode != Invalid || e.Name != "-c" {
+		t.Errorf("got %v for %s, want %v for -c", e.ErrorCode, e.Name, Invalid)
+	}
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		t.Errorf("errors.As unexpectedly found %v", ne)
+	}
+	if !errors.Is(err, e) {
+		t.Errorf("errors.Is did not find %v", e)
+	}
+	if errors.Is(err, &Error{}) {
+		t.Errorf("errors.Is found an error that is not in %v", err)
+	}
+}
+
+func TestCollectErrorsParse(t *testing.T) {
+	defer func(fn func(int)) { exit = fn }(exit)
+	exit = func(int) {}
+
+	s := New()
+	s.SetCollectErrors(true)
+	var count int
+	var verbose bool
+	var name string
+	s.FlagLong(&count, "count", 'c', "the count")
+	s.FlagLong(&verbose, "verbose", 'v', "be verbose")
+	s.FlagLong(&name, "name", 'n', "the name").Mandatory()
+	var b bytes.Buffer
+	s.SetOutput(&b)
+	s.SetUsage(func() { b.WriteString("usage\n") })
+	s.Parse([]string{"prog", "--verbsoe", "-c", "x"})
+	want := `unknown option: --verbsoe
+did you mean --verbose?
+not a valid number: x
+option -n is mandatory
+usage
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
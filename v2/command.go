@@ -0,0 +1,269 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// stdout allows tests to capture output to standard output.
+var stdout io.Writer = os.Stdout
+
+// A Command is a named command with its own set of options, such as the
+// "commit" in "git commit".  A Command may have subcommands, which are
+// dispatched to by name, as in "git remote add".
+//
+// Options declared in the Set returned by Persistent are inherited by all the
+// subcommands of the command.  They may appear either before or after the
+// name of the subcommand.  The options of a command that dispatches to a
+// subcommand, such as mandatory options, are checked after the subcommand has
+// parsed its arguments.
+//
+// A command with subcommands automatically has a help subcommand, unless one
+// is declared, where "help name ..." displays the usage of the named
+// subcommand.
+type Command struct {
+	Name    string   // Name of the command
+	Aliases []string // Alternate names of the command
+	Help    string   // One line description of the command
+	Hidden  bool     // Do not list the command in the usage
+
+	// Run is called with the command and its arguments remaining after
+	// parsing the options.  Run is not called on a command that has
+	// subcommands if a subcommand is named.
+	Run func(cmd *Command, args []string) error
+
+	set        *Set
+	persistent *Set
+	parent     *Command
+	commands   []*Command
+}
+
+// Set returns the set of options of c.
+func (c *Command) Set() *Set {
+	if c.set == nil {
+		c.set = New()
+	}
+	return c.set
+}
+
+// Persistent returns the set of options of c that are inherited by all the
+// subcommands of c.
+func (c *Command) Persistent() *Set {
+	if c.persistent == nil {
+		c.persistent = New()
+	}
+	return c.persistent
+}
+
+// Parent returns the command c is a subcommand of, or nil.
+func (c *Command) Parent() *Command { return c.parent }
+
+// Commands returns the subcommands of c sorted by name.
+func (c *Command) Commands() []*Command {
+	cmds := append([]*Command{}, c.commands...)
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
+// AddCommand adds cmds as subcommands of c.  The parameters displayed in the
+// usage of c are changed to "command [arguments ...]" if they have not been
+// set.
+func (c *Command) AddCommand(cmds ...*Command) {
+	for _, cmd := range cmds {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if c.Lookup(name) != nil {
+				fmt.Fprintf(stderr, "%s: command %s already declared\n", c.Set().Program(), name)
+				exit(1)
+			}
+		}
+		cmd.parent = c
+		c.commands = append(c.commands, cmd)
+	}
+	if set := c.Set(); set.parameters == defaultParameters {
+		set.parameters = "command [arguments ...]"
+	}
+}
+
+// Lookup returns the subcommand of c with the name or alias name, or nil.
+func (c *Command) Lookup(name string) *Command {
+	for _, cmd := range c.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// Execute parses args with the options of c and then either dispatches to
+// the subcommand named by the first remaining argument or calls c.Run.  The
+// first element of args is used to set the program name of c if it is not yet
+// set.  Subcommands use the program name of their parent followed by their
+// own name.
+//
+// Execute returns errors encountered while parsing, an Error with the
+// ErrorCode UnknownCommand or MissingCommand if a subcommand cannot be
+// dispatched to, or the error returned by Run.
+func (c *Command) Execute(args []string) error {
+	_, err := c.execute(args, nil)
+	return err
+}
+
+// Main calls Execute with args.  If Execute returns an error, Main displays
+// the error, as Parse does, and if it is an Error or Errors, the usage of the
+// command that failed, and then exits the program.  The output is written to
+// the output of the options of the command that failed (see SetOutput), which
+// is standard error unless set.
+func (c *Command) Main(args []string) {
+	cmd, err := c.execute(args, nil)
+	if err == nil {
+		return
+	}
+	set := cmd.inherit()
+	set.printError(err)
+	switch err.(type) {
+	case *Error, Errors:
+		cmd.PrintUsage(set.writer())
+	}
+	exit(1)
+}
+
+// execute executes c with args and returns the command that was executed
+// last.  Parents are the commands that dispatched to c.  The options of a
+// command with subcommands are not checked until it is known which command
+// runs, as persistent options may follow the name of the subcommand.
+func (c *Command) execute(args []string, parents []*Command) (*Command, error) {
+	set := c.inherit()
+	set.skipChecks = len(c.commands) > 0
+	err := set.Getopt(args, nil)
+	set.skipChecks = false
+	if err != nil {
+		return c, err
+	}
+	args = set.Args()
+	if len(c.commands) > 0 {
+		if len(args) > 0 {
+			if cmd := c.Lookup(args[0]); cmd != nil {
+				return cmd.execute(args, append(parents, c))
+			}
+		}
+		parents = append(parents, c)
+	}
+	for _, p := range parents {
+		if err := p.Set().checkParsed(); err != nil {
+			return p, err
+		}
+	}
+	if len(c.commands) == 0 {
+		if c.Run == nil {
+			return c, nil
+		}
+		return c, c.Run(c, args)
+	}
+	if len(args) == 0 {
+		if c.Run != nil {
+			return c, c.Run(c, args)
+		}
+		return c, missingCommand()
+	}
+	if args[0] == "help" {
+		return c.help(args[1:])
+	}
+	return c, unknownCommand(args[0])
+}
+
+// help displays the usage of the subcommand of c named by names on standard
+// output.
+func (c *Command) help(names []string) (*Command, error) {
+	cmd := c
+	for _, name := range names {
+		sub := cmd.Lookup(name)
+		if sub == nil {
+			return cmd, unknownCommand(name)
+		}
+		cmd = sub
+	}
+	cmd.PrintUsage(stdout)
+	return cmd, nil
+}
+
+// inherit adds the persistent options of c and its parents to the options of
+// c, sets the program name and, unless set, the output of c from its parent
+// if it is a subcommand, and returns the set of options of c.
+func (c *Command) inherit() *Set {
+	set := c.Set()
+	if c.parent != nil {
+		parent := c.parent.inherit()
+		if set.program == "" {
+			set.program = parent.Program() + " " + c.Name
+		}
+		if set.output == nil {
+			set.output = parent.output
+		}
+	}
+	for p := c; p != nil; p = p.parent {
+		if p.persistent != nil {
+			for _, opt := range p.persistent.options {
+				set.AddOption(opt)
+			}
+		}
+	}
+	return set
+}
+
+// PrintUsage prints the usage of c, including the list of its subcommands,
+// to w.
+func (c *Command) PrintUsage(w io.Writer) {
+	c.inherit().PrintUsage(w)
+	c.PrintCommands(w)
+}
+
+// PrintCommands prints the list of subcommands of c that are not hidden to w.
+func (c *Command) PrintCommands(w io.Writer) {
+	if len(c.commands) == 0 {
+		return
+	}
+	type entry struct{ name, help string }
+	var entries []entry
+	for _, cmd := range c.Commands() {
+		if cmd.Hidden {
+			continue
+		}
+		name := cmd.Name
+		if len(cmd.Aliases) > 0 {
+			name += " (" + strings.Join(cmd.Aliases, ", ") + ")"
+		}
+		entries = append(entries, entry{name, cmd.Help})
+	}
+	if c.Lookup("help") == nil {
+		entries = append(entries, entry{"help", "display help for a command"})
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	}
+	max := 4
+	for _, e := range entries {
+		if max < len(e.name) && len(e.name) <= HelpColumn-3 {
+			max = len(e.name)
+		}
+	}
+	fmt.Fprintln(w, "Commands:")
+	for _, e := range entries {
+		if e.help == "" {
+			fmt.Fprintf(w, " %s\n", e.name)
+		} else if len(e.name) <= max {
+			fmt.Fprintf(w, " %-*s  %s\n", max, e.name, e.help)
+		} else {
+			fmt.Fprintf(w, " %s\n %-*s  %s\n", e.name, max, " ", e.help)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// newTestCommand returns a command tree for "git" with the subcommands
+// commit (alias ci), remote (with the subcommands add and remove) and the
+// hidden command debug.  Each run records its command path and arguments in
+// ran.
+func newTestCommand(ran *[]string, verbose *bool, all *bool, force *bool) *Command {
+	run := func(cmd *Command, args []string) error {
+		*ran = append(*ran, cmd.Set().Program()+":"+strings.Join(args, ","))
+		return nil
+	}
+	root := &Command{Name: "git"}
+	root.Persistent().Flag(verbose, 'v', "be verbose")
+
+	commit := &Command{Name: "commit", Aliases: []string{"ci"}, Help: "record changes", Run: run}
+	commit.Set().Flag(all, 'a', "commit all")
+
+	remote := &Command{Name: "remote", Help: "manage remotes"}
+	add := &Command{Name: "add", Help: "add a remote", Run: run}
+	add.Set().Flag(force, 'f', "force")
+	remote.AddCommand(add, &Command{Name: "remove", Help: "remove a remote", Run: run})
+
+	debug := &Command{Name: "debug", Hidden: true, Run: run}
+	root.AddCommand(commit, remote, debug)
+	return root
+}
+
+func TestCommand(t *testing.T) {
+	for _, tt := range []struct {
+		where   string
+		in      []string
+		ran     string
+		verbose bool
+		all     bool
+		force   bool
+		err     string
+		code    ErrorCode
+	}{
+		{
+			where: loc(),
+			in:    []string{"git", "commit", "-a", "file"},
+			ran:   "git commit:file",
+			all:   true,
+		},
+		{
+			where:   loc(),
+			in:      []string{"git", "-v", "ci", "file"},
+			ran:     "git commit:file",
+			verbose: true,
+		},
+		{
+			where:   loc(),
+			in:      []string{"git", "remote", "add", "-v", "-f", "origin"},
+			ran:     "git remote add:origin",
+			verbose: true,
+			force:   true,
+		},
+		{
+			where: loc(),
+			in:    []string{"git", "debug"},
+			ran:   "git debug:",
+		},
+		{
+			where: loc(),
+			in:    []string{"git", "push"},
+			err:   "unknown command: push",
+			code:  UnknownCommand,
+		},
+		{
+			where: loc(),
+			in:    []string{"git", "remote"},
+			err:   "missing command",
+			code:  MissingCommand,
+		},
+		{
+			where: loc(),
+			in:    []string{"git", "commit", "-f"},
+			err:   "unknown option: -f",
+			code:  UnknownOption,
+		},
+	} {
+		var ran []string
+		var verbose, all, force bool
+		root := newTestCommand(&ran, &verbose, &all, &force)
+		err := root.Execute(tt.in)
+		switch {
+		case err == nil && tt.err != "":
+			t.Errorf("%s: did not get error %q", tt.where, tt.err)
+		case err != nil && err.Error() != tt.err:
+			t.Errorf("%s: got error %q, want %q", tt.where, err, tt.err)
+		case err != nil:
+			if e, ok := err.(*Error); !ok || e.ErrorCode != tt.code {
+				t.Errorf("%s: got error %#v, want ErrorCode %v", tt.where, err, tt.code)
+			}
+		}
+		if got := strings.Join(ran, " "); got != tt.ran {
+			t.Errorf("%s: ran %q, want %q", tt.where, got, tt.ran)
+		}
+		if verbose != tt.verbose || all != tt.all || force != tt.force {
+			t.Errorf("%s: got %v %v %v, want %v %v %v", tt.where, verbose, all, force, tt.verbose, tt.all, tt.force)
+		}
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	defer func(w io.Writer) { stdout = w }(stdout)
+	var buf bytes.Buffer
+	stdout = &buf
+
+	var ran []string
+	var verbose, all, force bool
+	root := newTestCommand(&ran, &verbose, &all, &force)
+
+	if err := root.Execute([]string{"git", "help"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `
+Usage: git [-v] command [arguments ...]
+ -v    be verbose
+Commands:
+ commit (ci)  record changes
+ help         display help for a command
+ remote       manage remotes
+`[1:]
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := root.Execute([]string{"git", "help", "remote", "add"}); err != nil {
+		t.Fatal(err)
+	}
+	want = `
+Usage: git remote add [-fv] [parameters ...]
+ -f    force
+ -v    be verbose
+`[1:]
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	err := root.Execute([]string{"git", "help", "push"})
+	if e, ok := err.(*Error); !ok || e.ErrorCode != UnknownCommand {
+		t.Errorf("got error %v, want UnknownCommand", err)
+	}
+}
+
+func TestCommandMain(t *testing.T) {
+	defer func(w io.Writer) { stderr = w }(stderr)
+	defer func(fn func(int)) { exit = fn }(exit)
+	var errbuf bytes.Buffer
+	stderr = &errbuf
+	exited := -1
+	exit = func(code int) { exited = code }
+
+	var ran []string
+	var verbose, all, force bool
+	root := newTestCommand(&ran, &verbose, &all, &force)
+	root.Lookup("commit").Set().SetCollectErrors(true)
+	var buf bytes.Buffer
+	root.Set().SetOutput(&buf)
+
+	root.Main([]string{"git", "commit", "-A", "-x"})
+	if exited != 1 {
+		t.Errorf("got exit code %d, want 1", exited)
+	}
+	want := `
+unknown option: -A
+did you mean -a?
+unknown option: -x
+Usage: git commit [-av] [parameters ...]
+ -a    commit all
+ -v    be verbose
+`[1:]
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if errbuf.Len() != 0 {
+		t.Errorf("unexpected output to stderr:\n%s", errbuf.String())
+	}
+}
+
+func TestCommandPersistentChecks(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		in    []string
+		ran   bool
+		err   string
+	}{
+		{loc(), []string{"prog", "-c", "x", "sub"}, true, ""},
+		{loc(), []string{"prog", "sub", "-c", "x"}, true, ""},
+		{loc(), []string{"prog", "sub"}, false, "option -c is mandatory"},
+		{loc(), []string{"prog", "-q", "sub", "-c", "x", "-v"}, false, "options -q and -v are mutually exclusive"},
+	} {
+		var config string
+		var quiet, verbose bool
+		ran := false
+		root := &Command{Name: "prog"}
+		root.Persistent().FlagLong(&config, "config", 'c', "config file").Mandatory()
+		root.Set().Flag(&quiet, 'q', "be quiet").SetGroup("level")
+		root.Persistent().Flag(&verbose, 'v', "be verbose").SetGroup("level")
+		root.AddCommand(&Command{Name: "sub", Run: func(*Command, []string) error {
+			ran = true
+			return nil
+		}})
+		err := root.Execute(tt.in)
+		var es string
+		if err != nil {
+			es = err.Error()
+		}
+		if es != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.where, es, tt.err)
+		}
+		if ran != tt.ran {
+			t.Errorf("%s: got ran %v, want %v", tt.where, ran, tt.ran)
+		}
+	}
+}
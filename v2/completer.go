@@ -0,0 +1,364 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// A Completer returns the possible completions of prefix, such as the names
+// of the hosts or branches that start with prefix.  Completions that do not
+// start with prefix are ignored.
+type Completer func(prefix string) []string
+
+// completeArg is the first argument of a completion request (see
+// SetDynamicCompletion).
+const completeArg = "__complete"
+
+// CompleteFiles is a Completer that completes the names of files and
+// directories.  Directories are completed with a trailing slash.  Hidden files
+// are only completed if prefix names a hidden file.
+func CompleteFiles(prefix string) []string {
+	return completePath(prefix, false)
+}
+
+// CompleteDirs is a Completer that completes the names of directories, with a
+// trailing slash.
+func CompleteDirs(prefix string) []string {
+	return completePath(prefix, true)
+}
+
+// completePath returns the paths that start with prefix.  Only directories
+// are returned if dirs is true.
+func completePath(prefix string, dirs bool) []string {
+	dir, file := filepath.Split(prefix)
+	d := dir
+	if d == "" {
+		d = "."
+	}
+	entries, err := ioutil.ReadDir(d)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, fi := range entries {
+		name := fi.Name()
+		if !strings.HasPrefix(name, file) || (name[0] == '.' && !strings.HasPrefix(file, ".")) {
+			continue
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(filepath.Join(d, name)); err == nil {
+				fi = target
+			}
+		}
+		switch {
+		case fi.IsDir():
+			name += "/"
+		case dirs:
+			continue
+		}
+		paths = append(paths, dir+name)
+	}
+	return paths
+}
+
+// CompleteValues returns a Completer that completes the listed values.
+func CompleteValues(values ...string) Completer {
+	return func(string) []string { return values }
+}
+
+// SetDynamicCompletion sets the dynamic completion mode of the command line
+// options.  See Set.SetDynamicCompletion for details.
+func SetDynamicCompletion(dynamic bool) {
+	CommandLine.SetDynamicCompletion(dynamic)
+}
+
+// SetDynamicCompletion sets the dynamic completion mode of s.  When dynamic is
+// true, Getopt answers completion requests of the form
+//
+//	prog __complete [argument ...] word
+//
+// by writing the completions of word (see Complete) to standard output, one
+// per line, and exiting the program.  The shell scripts written by
+// WriteCompletionShim make these requests, so values can be completed from
+// the state of the program at the time, such as by the Completer of an option
+// (see SetCompleter).  When dynamic completion is enabled, the option added by
+// AddCompletionOption writes the script from WriteCompletionShim rather than
+// WriteCompletion.
+func (s *Set) SetDynamicCompletion(dynamic bool) {
+	s.dynamicCompletion = dynamic
+}
+
+// SetOperandCompleter calls SetOperandCompleter on the command line options.
+func SetOperandCompleter(c Completer) {
+	CommandLine.SetOperandCompleter(c)
+}
+
+// SetOperandCompleter sets the Completer of the operands of s that are not
+// declared positional parameters.  By default, operands are completed by
+// CompleteFiles.
+func (s *Set) SetOperandCompleter(c Completer) {
+	s.operandCompleter = c
+}
+
+// Complete calls Complete on the command line options.
+func Complete(args []string) []string {
+	return CommandLine.Complete(args)
+}
+
+// Complete returns the sorted completions of the last element of args, which
+// are the arguments of the program, not including the program name, up to
+// and including the word being completed.  The preceding arguments are parsed
+// as by Getopt, without setting any options, to determine if the word is the
+// name of an option, the value of an option or an operand:
+//
+// An option name is completed to the names of the options of s that are not
+// hidden or deprecated, leaving out the options of a mutually exclusive group that already
+// has an option in args.
+//
+// An option value, including a value attached to the option as in
+// --name=value, is completed by the Completer of the option (see
+// SetCompleter).  If it has none, the values of an Enum are completed and
+// values that may be file names are completed by CompleteFiles.
+//
+// An operand is completed by the Completer of the positional parameter it
+// would be assigned to (see Positional), or by the Completer set by
+// SetOperandCompleter.
+func (s *Set) Complete(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	word := args[len(args)-1]
+
+	defer func(program string) { s.program = program }(s.program)
+	p := s.Parser(append([]string{s.program}, args[:len(args)-1]...))
+	p.dryRun = true
+	groups := map[string]bool{}
+	operands := 0
+	var value *option // the option that word is the value of
+	for p.Next() {
+		ev := p.Event()
+		switch ev.Kind {
+		case OptionEvent:
+			if g := ev.Option.(*option).group; g != "" {
+				groups[g] = true
+			}
+		case OperandEvent:
+			operands++
+		case ErrorEvent:
+			if err, ok := ev.Err.(*Error); ok && err.ErrorCode == MissingParameter {
+				value = ev.Option.(*option)
+			}
+		}
+	}
+
+	var completions []string
+	switch {
+	case value != nil:
+		completions = value.complete("", word)
+	case p.options && strings.HasPrefix(word, "-"):
+		completions = s.completeOption(word, groups)
+	default:
+		completions = s.completeOperand(operands, word)
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// completeOption returns the completions of word, which starts with a dash,
+// as an option.  Options in groups are not completed.
+func (s *Set) completeOption(word string, groups map[string]bool) []string {
+	// A value attached to a long option.
+	if long, dash := s.longName(word); long != "" {
+		if e := strings.IndexRune(long, '='); e > 0 {
+			opt, _, negated, err := s.lookupLong(long[:e], dash)
+			if err != nil || negated != "" || opt.flag {
+				return nil
+			}
+			return opt.complete(dash+long[:e+1], long[e+1:])
+		}
+	}
+
+	// A value attached to a short option.
+	if s.singleDash != SingleDashStrict && !strings.HasPrefix(word, "--") {
+		for x, c := range word[1:] {
+			opt := s.shortOptions[c]
+			if opt == nil {
+				break
+			}
+			if n := x + 1 + len(string(c)); !opt.flag && n < len(word) {
+				return opt.complete(word[:n], word[n:])
+			}
+		}
+	}
+
+	var completions []string
+	for _, c := range s.completionOptions() {
+		if groups[c.opt.group] {
+			continue
+		}
+		for _, name := range c.names() {
+			if strings.HasPrefix(name, word) {
+				completions = append(completions, name)
+			}
+		}
+	}
+	return completions
+}
+
+// completeOperand returns the completions of word as operand n.
+func (s *Set) completeOperand(n int, word string) []string {
+	if len(s.positionals) > 0 {
+		last := s.positionals[len(s.positionals)-1]
+		switch {
+		case n < len(s.positionals):
+			return s.positionals[n].complete("", word)
+		case last.variadic:
+			return last.complete("", word)
+		}
+		return nil
+	}
+	c := s.operandCompleter
+	if c == nil {
+		c = CompleteFiles
+	}
+	return completeWith(c, "", word)
+}
+
+// complete returns the completions of word as the value of o, each preceded by
+// prefix.
+func (o *option) complete(prefix, word string) []string {
+	c := o.completer
+	if c == nil {
+		values, files := completionValues(o)
+		switch {
+		case len(values) > 0:
+			c = CompleteValues(values...)
+		case files:
+			c = CompleteFiles
+		default:
+			return nil
+		}
+	}
+	return completeWith(c, prefix, word)
+}
+
+// completeWith returns the completions of word by c, each preceded by prefix.
+func completeWith(c Completer, prefix, word string) []string {
+	var completions []string
+	for _, v := range c(word) {
+		if strings.HasPrefix(v, word) {
+			completions = append(completions, prefix+v)
+		}
+	}
+	return completions
+}
+
+// completionRequest answers the completion request in args, if any, and
+// returns true.  See SetDynamicCompletion.
+func (s *Set) completionRequest(args []string) bool {
+	if !s.dynamicCompletion || len(args) < 2 || args[1] != completeArg {
+		return false
+	}
+	if s.program == "" {
+		s.program = path.Base(args[0])
+	}
+	for _, c := range s.Complete(args[2:]) {
+		fmt.Fprintln(stdout, c)
+	}
+	exit(0)
+	return true
+}
+
+// WriteCompletionShim calls WriteCompletionShim on the command line options.
+func WriteCompletionShim(w io.Writer, shell string) error {
+	return CommandLine.WriteCompletionShim(w, shell)
+}
+
+// WriteCompletionShim writes a script to w that provides tab completion of
+// the arguments of the program of s for shell, which must be one of "bash",
+// "zsh" or "fish".  Unlike WriteCompletion, the script asks the program for
+// the completions each time (see SetDynamicCompletion), so the program must
+// have dynamic completion enabled.
+func (s *Set) WriteCompletionShim(w io.Writer, shell string) error {
+	if s.program == "" {
+		return fmt.Errorf("program name not set")
+	}
+	fn := s.completionFunc()
+	prog := shellQuote(s.program)
+	var script string
+	switch shell {
+	case "bash":
+		script = fmt.Sprintf(bashShim, s.program, fn, bashWords, completeArg, fn, prog)
+	case "zsh":
+		script = fmt.Sprintf(zshShim, s.program, fn, completeArg, shellQuote(fn), fn, fn, prog)
+	case "fish":
+		script = fmt.Sprintf(fishShim, s.program, fn, completeArg, prog, fn)
+	default:
+		return fmt.Errorf("unsupported shell %q, must be one of %s", shell, strings.Join(completionShells, ", "))
+	}
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// bashWords sets words to the words of the command line up to the cursor, cur
+// to the word being completed and prefix to the part of cur that bash does
+// not replace, as bash splits words at characters such as "=".
+const bashWords = `	local line="${COMP_LINE:0:COMP_POINT}" words
+	read -ra words <<< "$line"
+	[[ -z $line || $line == *[[:space:]] ]] && words+=("")
+	local cur="${words[${#words[@]}-1]}"
+	local prefix="${cur%"${COMP_WORDS[COMP_CWORD]}"}"
+`
+
+const bashShim = `# bash completion for %s
+%s() {
+%s	local IFS=$'\n' c
+	COMPREPLY=()
+	for c in $("${words[0]}" %s "${words[@]:1}" 2>/dev/null); do
+		COMPREPLY+=("${c#"$prefix"}")
+	done
+	if [[ ${#COMPREPLY[@]} == 1 && $COMPREPLY == */ ]]; then
+		compopt -o nospace
+	fi
+}
+complete -F %s %s
+`
+
+const zshShim = `#compdef %s
+%s() {
+	local c
+	for c in "${(@f)$("${words[1]}" %s "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+		[[ -z $c ]] && continue
+		if [[ $c == */ ]]; then
+			compadd -S '' -- "$c"
+		else
+			compadd -- "$c"
+		fi
+	done
+}
+if [ "$funcstack[1]" = %s ]; then
+	%s "$@"
+else
+	compdef %s %s
+fi
+`
+
+const fishShim = `# fish completion for %s
+function %s
+	set -l words (commandline -opc)
+	set -l prog $words[1]
+	set -e words[1]
+	$prog %s $words (commandline -ct)"" 2>/dev/null
+end
+complete -c %s -f -a '(%s)'
+`
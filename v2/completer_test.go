@@ -0,0 +1,204 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "getopt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.txt", "ab.txt", "b/", ".hidden"} {
+		p := filepath.Join(dir, name)
+		if strings.HasSuffix(name, "/") {
+			err = os.Mkdir(p, 0755)
+		} else {
+			err = ioutil.WriteFile(p, nil, 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir += "/"
+
+	hosts := func(prefix string) []string {
+		return []string{"alpha.example.com", "beta.example.com"}
+	}
+
+	for _, tt := range []struct {
+		where string
+		args  []string
+		out   []string
+	}{
+		{loc(), []string{dir}, []string{dir + "a.txt", dir + "ab.txt", dir + "b/"}},
+		{loc(), []string{dir + "a."}, []string{dir + "a.txt"}},
+		{loc(), []string{"-"}, []string{"--color", "--dir", "--host", "--mode", "--no-color", "--output", "-H", "-a", "-b", "-m", "-o", "-v", "--verbose", "--alpha", "--beta"}},
+		{loc(), []string{"--h"}, []string{"--host"}},
+		{loc(), []string{"-a", "-"}, []string{"--color", "--dir", "--host", "--mode", "--no-color", "--output", "-H", "-m", "-o", "-v", "--verbose"}},
+		{loc(), []string{"--mode", ""}, []string{"fast", "slow"}},
+		{loc(), []string{"--mode", "f"}, []string{"fast"}},
+		{loc(), []string{"--mode=s"}, []string{"--mode=slow"}},
+		{loc(), []string{"-ms"}, []string{"-mslow"}},
+		{loc(), []string{"-vm", ""}, []string{"fast", "slow"}},
+		{loc(), []string{"-H", "b"}, []string{"beta.example.com"}},
+		{loc(), []string{"--host="}, []string{"--host=alpha.example.com", "--host=beta.example.com"}},
+		{loc(), []string{"-o", dir + "a"}, []string{dir + "a.txt", dir + "ab.txt"}},
+		{loc(), []string{"-o", dir + "."}, []string{dir + ".hidden"}},
+		{loc(), []string{"--dir", dir}, []string{dir + "b/"}},
+		{loc(), []string{"--", "-"}, nil},
+		{loc(), []string{"--no-color=x"}, nil},
+		{loc(), []string{"-x", dir + "b"}, []string{dir + "b/"}},
+		{loc(), []string{"-o", "-"}, nil},
+	} {
+		s := New()
+		var (
+			color, alpha, beta bool
+			dir, host          string
+		)
+		s.BoolLong("verbose", 'v', "be verbose")
+		s.FlagLong(&color, "color", 0, "use color").Negatable()
+		s.StringLong("output", 'o', "", "output file")
+		s.FlagLong(&dir, "dir", 0, "directory").SetCompleter(CompleteDirs)
+		s.FlagLong(&host, "host", 'H', "host").SetCompleter(hosts)
+		s.EnumLong("mode", 'm', []string{"slow", "fast"}, "", "speed")
+		s.FlagLong(&alpha, "alpha", 'a', "alpha").SetGroup("greek")
+		s.FlagLong(&beta, "beta", 'b', "beta").SetGroup("greek")
+		s.AddCompletionOption()
+		sort.Strings(tt.out)
+		if out := s.Complete(tt.args); badSlice(out, tt.out) {
+			t.Errorf("%s: got %q, want %q", tt.where, out, tt.out)
+		}
+		if s.Lookup("verbose").Seen() || s.Lookup('m').String() != "" {
+			t.Errorf("%s: options were set", tt.where)
+		}
+	}
+}
+
+func TestCompletePositionals(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		args  []string
+		out   []string
+	}{
+		{loc(), []string{""}, []string{"add", "remove"}},
+		{loc(), []string{"-v", "r"}, []string{"remove"}},
+		{loc(), []string{"add", ""}, []string{"alpha", "beta"}},
+		{loc(), []string{"add", "alpha", "b"}, []string{"beta"}},
+		{loc(), []string{"-"}, []string{"-v"}},
+		{loc(), []string{"add", "alpha", "beta", "-"}, nil},
+	} {
+		s := New()
+		var action string
+		var names []string
+		s.Bool('v', "be verbose")
+		s.Positional(&action, "action").SetCompleter(CompleteValues("add", "remove"))
+		s.Positional(&names, "name").SetVariadic().SetCompleter(CompleteValues("alpha", "beta"))
+		if out := s.Complete(tt.args); badSlice(out, tt.out) {
+			t.Errorf("%s: got %q, want %q", tt.where, out, tt.out)
+		}
+	}
+
+	s := New()
+	s.SetPermute(true)
+	s.SetOperandCompleter(CompleteValues("one", "two"))
+	s.Bool('v', "be verbose")
+	for _, args := range [][]string{{"t"}, {"x", "-v", "t"}} {
+		if out := s.Complete(args); badSlice(out, []string{"two"}) {
+			t.Errorf("%q: got %q, want %q", args, out, []string{"two"})
+		}
+	}
+}
+
+func TestCompletionRequest(t *testing.T) {
+	reset()
+	defer func(w io.Writer) { stdout = w }(stdout)
+	var b bytes.Buffer
+	stdout = &b
+	exited := -1
+	defer func(fn func(int)) { exit = fn }(exit)
+	exit = func(code int) { exited = code }
+
+	var mode string
+	Bool('v', "be verbose")
+	FlagLong(&mode, "mode", 'm', "mode").SetCompleter(CompleteValues("fast", "slow"))
+
+	// Without dynamic completion __complete is an operand.
+	parse([]string{"prog", "__complete", "-m", ""})
+	if exited != -1 || b.Len() != 0 {
+		t.Errorf("completion request answered")
+	}
+	if args := Args(); badSlice(args, []string{"__complete", "-m", ""}) {
+		t.Errorf("got args %q", args)
+	}
+
+	reset()
+	FlagLong(&mode, "mode", 'm', "mode").SetCompleter(CompleteValues("fast", "slow"))
+	SetDynamicCompletion(true)
+	parse([]string{"prog", "__complete", "-m", ""})
+	if exited != 0 {
+		t.Errorf("got exit code %d, want 0", exited)
+	}
+	if out := b.String(); out != "fast\nslow\n" {
+		t.Errorf("got %q, want %q", out, "fast\nslow\n")
+	}
+	if errorString != "" {
+		t.Errorf("unexpected error:\n%s", errorString)
+	}
+}
+
+func TestCompletionShim(t *testing.T) {
+	s := New()
+	s.SetProgram("my-prog")
+	for _, tt := range []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{
+			"_my_prog() {",
+			"\tfor c in $(\"${words[0]}\" __complete \"${words[@]:1}\" 2>/dev/null); do",
+			"complete -F _my_prog 'my-prog'",
+		}},
+		{"zsh", []string{
+			"#compdef my-prog",
+			"\tfor c in \"${(@f)$(\"${words[1]}\" __complete \"${(@)words[2,CURRENT]}\" 2>/dev/null)}\"; do",
+			"\tcompdef _my_prog 'my-prog'",
+		}},
+		{"fish", []string{
+			"function _my_prog",
+			"\t$prog __complete $words (commandline -ct)\"\" 2>/dev/null",
+			"complete -c 'my-prog' -f -a '(_my_prog)'",
+		}},
+	} {
+		var b bytes.Buffer
+		if err := s.WriteCompletionShim(&b, tt.shell); err != nil {
+			t.Errorf("%s: %v", tt.shell, err)
+			continue
+		}
+		lines := strings.Split(b.String(), "\n")
+		for _, want := range tt.want {
+			found := false
+			for _, line := range lines {
+				found = found || line == want
+			}
+			if !found {
+				t.Errorf("%s: missing line %q in:\n%s", tt.shell, want, b.String())
+			}
+		}
+	}
+	if err := s.WriteCompletionShim(ioutil.Discard, "csh"); err == nil {
+		t.Errorf("csh: did not get an error")
+	}
+}
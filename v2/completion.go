@@ -0,0 +1,420 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Shells supported by WriteCompletion.
+var completionShells = []string{"bash", "fish", "zsh"}
+
+// WriteCompletion calls WriteCompletion on the command line options.
+func WriteCompletion(w io.Writer, shell string) error {
+	return CommandLine.WriteCompletion(w, shell)
+}
+
+// WriteCompletion writes a script to w that provides tab completion of the
+// options of s for shell, which must be one of "bash", "zsh" or "fish".  The
+// script completes the short and long names of the options and the values of
+// Enum options.  Options that take a value (see IsFlag and SetOptional)
+// complete the value rather than another option, and once an option in a
+// mutually exclusive group (see SetGroup) is on the command line, the other
+// options in the group are no longer offered.  Hidden and deprecated options
+// are not completed.
+//
+// The program name of s is used as the name of the command being completed,
+// so it must be set, by SetProgram or by parsing, before calling
+// WriteCompletion.
+func (s *Set) WriteCompletion(w io.Writer, shell string) error {
+	if s.program == "" {
+		return fmt.Errorf("program name not set")
+	}
+	opts := s.completionOptions()
+	var buf bytes.Buffer
+	switch shell {
+	case "bash":
+		s.writeBash(&buf, opts)
+	case "zsh":
+		s.writeZsh(&buf, opts)
+	case "fish":
+		s.writeFish(&buf, opts)
+	default:
+		return fmt.Errorf("unsupported shell %q, must be one of %s", shell, strings.Join(completionShells, ", "))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// A completionValue is the value of the option added by AddCompletionOption.
+// Setting it writes the completion script for the shell it is set to on
+// standard output and exits the program.
+type completionValue struct {
+	s     *Set
+	shell string
+}
+
+func (c *completionValue) Set(value string, opt Option) error {
+	if value == "" {
+		return nil
+	}
+	c.shell = value
+	write := c.s.WriteCompletion
+	if c.s.dynamicCompletion {
+		write = c.s.WriteCompletionShim
+	}
+	if err := write(stdout, value); err != nil {
+		return err
+	}
+	exit(0)
+	return nil
+}
+
+func (c *completionValue) String() string { return c.shell }
+
+// AddCompletionOption calls AddCompletionOption on the command line options.
+func AddCompletionOption() Option {
+	return CommandLine.AddCompletionOption()
+}
+
+// AddCompletionOption adds the hidden option --completion=shell to s.  When it
+// is parsed, the completion script for shell (see WriteCompletion, or
+// WriteCompletionShim if dynamic completion is enabled) is written to standard
+// output and the program exits.  A program named prog can then be
+// set up for completion in bash with:
+//
+//	source <(prog --completion=bash)
+func (s *Set) AddCompletionOption() Option {
+	opt := s.FlagLong(&completionValue{s: s}, "completion", 0, "write the shell completion script", "shell")
+	opt.(*option).hidden = true
+	return opt
+}
+
+// A completionOption describes how to complete an option.
+type completionOption struct {
+	opt     *option
+	short   []string // short names, e.g. "-a", including aliases
+	long    []string // long names, e.g. "--alpha", including aliases
+	negated []string // negated long names, e.g. "--no-alpha"
+	value   bool     // true if the option takes a value
+	values  []string // the possible values, if known
+	files   bool     // true if the value should be completed as a file
+}
+
+// names returns all the names of c.
+func (c *completionOption) names() []string {
+	return append(c.valueNames(), c.negated...)
+}
+
+// valueNames returns the names of c that may take a value.
+func (c *completionOption) valueNames() []string {
+	return append(append([]string{}, c.short...), c.long...)
+}
+
+// completionOptions returns the options of s that are completed.
+func (s *Set) completionOptions() []*completionOption {
+	var opts []*completionOption
+	dash := s.longDash()
+	s.VisitAll(func(o Option) {
+		opt := o.(*option)
+		if opt.hidden || opt.deprecation != nil {
+			return
+		}
+		c := &completionOption{opt: opt, value: !opt.flag}
+		for _, r := range opt.shortNames() {
+			c.short = append(c.short, "-"+string(r))
+		}
+		for _, l := range opt.longNames() {
+			c.long = append(c.long, dash+l)
+			if n := s.negationFor(opt).negate(l); n != "" {
+				c.negated = append(c.negated, dash+n)
+			}
+		}
+		if c.value {
+			c.values, c.files = completionValues(opt)
+		}
+		opts = append(opts, c)
+	})
+	return opts
+}
+
+// completionValues returns the values opt may be set to, if known, and
+// whether the value of opt should be completed as a file name.
+func completionValues(opt *option) (values []string, files bool) {
+	if e, ok := opt.value.(*enumValue); ok {
+		enumValuesMu.Lock()
+		for v := range enumValues[e] {
+			values = append(values, v)
+		}
+		enumValuesMu.Unlock()
+		sort.Strings(values)
+		return values, false
+	}
+	switch genericValue(opt.value).(type) {
+	case *bool:
+		return []string{"false", "true"}, false
+	case *int, *int8, *int16, *int32, *int64,
+		*uint, *uint8, *uint16, *uint32, *uint64,
+		*float32, *float64, *time.Duration:
+		return nil, false
+	}
+	switch opt.value.(type) {
+	case *signed, *unsigned, *counterValue:
+		return nil, false
+	}
+	return nil, true
+}
+
+// completionGroups returns the mutually exclusive groups of opts, sorted by
+// name, and the options in each group.
+func completionGroups(opts []*completionOption) ([]string, map[string][]*completionOption) {
+	groups := map[string][]*completionOption{}
+	var names []string
+	for _, c := range opts {
+		if g := c.opt.group; g != "" {
+			if groups[g] == nil {
+				names = append(names, g)
+			}
+			groups[g] = append(groups[g], c)
+		}
+	}
+	sort.Strings(names)
+	return names, groups
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// completionFunc returns the name of the shell function that completes s.
+func (s *Set) completionFunc() string {
+	return "_" + nonIdentifier.ReplaceAllString(s.program, "_")
+}
+
+// shellQuote returns s quoted with single quotes for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func (s *Set) writeBash(w io.Writer, opts []*completionOption) {
+	fn := s.completionFunc()
+	fmt.Fprintf(w, "# bash completion for %s\n", s.program)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprint(w, bashWords)
+	fmt.Fprintf(w, "\tlocal prev=\"${words[${#words[@]}-2]}\"\n")
+	fmt.Fprintf(w, "\tCOMPREPLY=()\n")
+	fmt.Fprintf(w, "\t%s_reply\n", fn)
+	fmt.Fprintf(w, "\tCOMPREPLY=(\"${COMPREPLY[@]#\"$prefix\"}\")\n")
+	fmt.Fprintf(w, "}\n")
+
+	// The completions are computed by a separate function, which can
+	// return as soon as they are known.
+	fmt.Fprintf(w, "%s_reply() {\n", fn)
+
+	// Complete the value of an option given as the next word.
+	var cases []string
+	for _, c := range opts {
+		if !c.value || c.opt.optional {
+			continue
+		}
+		cases = append(cases, fmt.Sprintf("\t%s)\n\t\t%s\n\t\treturn\n\t\t;;\n", strings.Join(c.valueNames(), "|"), bashValues(c, "")))
+	}
+	if len(cases) > 0 {
+		fmt.Fprintf(w, "\tcase \"$prev\" in\n%s\tesac\n", strings.Join(cases, ""))
+	}
+
+	// Complete the value of a long option given as --option=value.
+	cases = nil
+	for _, c := range opts {
+		if !c.value {
+			continue
+		}
+		for _, name := range c.long {
+			cases = append(cases, fmt.Sprintf("\t%s=*)\n\t\t%s\n\t\treturn\n\t\t;;\n", name, bashValues(c, name+"=")))
+		}
+	}
+	if len(cases) > 0 {
+		fmt.Fprintf(w, "\tcase \"$cur\" in\n%s\tesac\n", strings.Join(cases, ""))
+	}
+
+	// Build the list of options, leaving out the mutually exclusive
+	// groups that already have an option on the command line.
+	var names []string
+	for _, c := range opts {
+		if c.opt.group == "" {
+			names = append(names, c.names()...)
+		}
+	}
+	fmt.Fprintf(w, "\tlocal opts=%s\n", shellQuote(strings.Join(names, " ")))
+	groups, members := completionGroups(opts)
+	for x, g := range groups {
+		var names, patterns []string
+		for _, c := range members[g] {
+			names = append(names, c.names()...)
+			patterns = append(patterns, c.names()...)
+			for _, n := range c.long {
+				if c.value {
+					patterns = append(patterns, n+"=*")
+				}
+			}
+		}
+		fmt.Fprintf(w, "\t# group %s\n", g)
+		fmt.Fprintf(w, "\tlocal group%d=1 w\n", x)
+		fmt.Fprintf(w, "\tfor w in \"${words[@]:1:${#words[@]}-2}\"; do\n")
+		fmt.Fprintf(w, "\t\tcase \"$w\" in %s) group%d=0;; esac\n", strings.Join(patterns, "|"), x)
+		fmt.Fprintf(w, "\tdone\n")
+		fmt.Fprintf(w, "\t[ $group%d = 1 ] && opts=\"$opts %s\"\n", x, strings.Join(names, " "))
+	}
+	fmt.Fprintf(w, "\tcase \"$cur\" in\n")
+	fmt.Fprintf(w, "\t-*)\n\t\tCOMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n\t\t;;\n")
+	fmt.Fprintf(w, "\t*)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\t;;\n")
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, shellQuote(s.program))
+}
+
+// bashValues returns the bash command to complete the value of c.  Prefix is
+// the text that precedes the value in the current word.
+func bashValues(c *completionOption, prefix string) string {
+	cur := `"$cur"`
+	p := ""
+	if prefix != "" {
+		cur = `"${cur#` + prefix + `}"`
+		p = " -P " + shellQuote(prefix)
+	}
+	switch {
+	case len(c.values) > 0:
+		return fmt.Sprintf("COMPREPLY=($(compgen%s -W %s -- %s))", p, shellQuote(strings.Join(c.values, " ")), cur)
+	case c.files:
+		return fmt.Sprintf("COMPREPLY=($(compgen%s -f -- %s))", p, cur)
+	}
+	return ":"
+}
+
+func (s *Set) writeZsh(w io.Writer, opts []*completionOption) {
+	fn := s.completionFunc()
+	fmt.Fprintf(w, "#compdef %s\n", s.program)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\t_arguments -s -S \\\n")
+	_, members := completionGroups(opts)
+	for _, c := range opts {
+		excl := ""
+		if g := c.opt.group; g != "" {
+			var names []string
+			for _, m := range members[g] {
+				names = append(names, m.names()...)
+			}
+			excl = "(" + strings.Join(names, " ") + ")"
+		}
+		help := "[" + zshEscape(c.opt.help) + "]"
+		action := ""
+		if c.value {
+			name := c.opt.name
+			if name == "" {
+				name = "value"
+			}
+			switch {
+			case len(c.values) > 0:
+				action = ":" + zshEscape(name) + ":(" + strings.Join(c.values, " ") + ")"
+			case c.files:
+				action = ":" + zshEscape(name) + ":_files"
+			default:
+				action = ":" + zshEscape(name) + ": "
+			}
+		}
+		for _, n := range c.short {
+			spec := n
+			switch {
+			case !c.value:
+			case c.opt.optional:
+				spec += "-"
+			default:
+				spec += "+"
+			}
+			fmt.Fprintf(w, "\t\t%s \\\n", shellQuote(excl+spec+help+action))
+		}
+		for _, n := range c.long {
+			spec := n
+			switch {
+			case !c.value:
+			case c.opt.optional:
+				spec += "=-"
+			default:
+				spec += "="
+			}
+			fmt.Fprintf(w, "\t\t%s \\\n", shellQuote(excl+spec+help+action))
+		}
+		for _, n := range c.negated {
+			fmt.Fprintf(w, "\t\t%s \\\n", shellQuote(excl+n+help))
+		}
+	}
+	fmt.Fprintf(w, "\t\t'*:argument:_files'\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "if [ \"$funcstack[1]\" = %s ]; then\n\t%s \"$@\"\nelse\n\tcompdef %s %s\nfi\n", shellQuote(fn), fn, fn, shellQuote(s.program))
+}
+
+// zshEscape escapes the characters in s that are special in _arguments specs.
+func zshEscape(s string) string {
+	s = strings.Replace(s, "\n", " ", -1)
+	for _, c := range []string{`\`, `[`, `]`, `:`} {
+		s = strings.Replace(s, c, `\`+c, -1)
+	}
+	return s
+}
+
+func (s *Set) writeFish(w io.Writer, opts []*completionOption) {
+	fmt.Fprintf(w, "# fish completion for %s\n", s.program)
+	prog := "complete -c " + shellQuote(s.program)
+	long := "-l"
+	if s.longDash() == "-" {
+		long = "-o"
+	}
+	_, members := completionGroups(opts)
+	for _, c := range opts {
+		cond := ""
+		if g := c.opt.group; g != "" {
+			var seen []string
+			for _, m := range members[g] {
+				for _, n := range m.short {
+					seen = append(seen, "-s "+n[1:])
+				}
+				for _, n := range append(m.long, m.negated...) {
+					seen = append(seen, long+" "+strings.TrimLeft(n, "-"))
+				}
+			}
+			cond = " -n " + shellQuote("not __fish_seen_argument "+strings.Join(seen, " "))
+		}
+		args := prog + cond
+		for _, n := range c.short {
+			args += " -s " + shellQuote(n[1:])
+		}
+		for _, n := range c.long {
+			args += " " + long + " " + shellQuote(strings.TrimLeft(n, "-"))
+		}
+		switch {
+		case !c.value:
+		case c.opt.optional:
+			// fish cannot complete values that must be attached
+		case len(c.values) > 0:
+			args += " -x -a " + shellQuote(strings.Join(c.values, " "))
+		case c.files:
+			args += " -r -F"
+		default:
+			args += " -x"
+		}
+		if h := strings.TrimSpace(c.opt.help); h != "" {
+			args += " -d " + shellQuote(strings.Replace(h, "\n", " ", -1))
+		}
+		fmt.Fprintln(w, args)
+		// The negated names are flags on their own.
+		for _, n := range c.negated {
+			fmt.Fprintf(w, "%s%s %s %s\n", prog, cond, long, shellQuote(strings.TrimLeft(n, "-")))
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	for _, tt := range []struct {
+		shell string
+		want  []string // lines that must be in the script
+		skip  []string // strings that must not be in the script
+	}{
+		{
+			shell: "bash",
+			want: []string{
+				"_my_prog() {",
+				"\t-m|--mode)",
+				"\t\tCOMPREPLY=($(compgen -W 'fast slow' -- \"$cur\"))",
+				"\t-o|--output)",
+				"\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))",
+				"\t--mode=*)",
+				"\t\tCOMPREPLY=($(compgen -P '--mode=' -W 'fast slow' -- \"${cur#--mode=}\"))",
+				"\t--debug=*)",
+				"\tlocal opts='--color --no-color -c --count --debug -m --mode -o --output -v --verbose'",
+				"\t\tcase \"$w\" in -a|--alpha|-b|--beta) group0=0;; esac",
+				"\t[ $group0 = 1 ] && opts=\"$opts -a --alpha -b --beta\"",
+				"complete -F _my_prog 'my-prog'",
+			},
+			skip: []string{"-d|--debug", "--completion"},
+		},
+		{
+			shell: "zsh",
+			want: []string{
+				"#compdef my-prog",
+				"\t\t'(-a --alpha -b --beta)-a[alpha]' \\",
+				"\t\t'(-a --alpha -b --beta)--beta[beta]' \\",
+				"\t\t'-c+[count]:value: ' \\",
+				"\t\t'--color[use color]' \\",
+				"\t\t'--no-color[use color]' \\",
+				"\t\t'--debug=-[debug level]:value:_files' \\",
+				"\t\t'-m+[speed]:value:(fast slow)' \\",
+				"\t\t'--output=[output file]:file:_files' \\",
+				"\tcompdef _my_prog 'my-prog'",
+			},
+			skip: []string{"--completion"},
+		},
+		{
+			shell: "fish",
+			want: []string{
+				"complete -c 'my-prog' -n 'not __fish_seen_argument -s a -l alpha -s b -l beta' -s 'a' -l 'alpha' -d 'alpha'",
+				"complete -c 'my-prog' -l 'color' -d 'use color'",
+				"complete -c 'my-prog' -l 'no-color'",
+				"complete -c 'my-prog' -s 'c' -l 'count' -x -d 'count'",
+				"complete -c 'my-prog' -l 'debug' -d 'debug level'",
+				"complete -c 'my-prog' -s 'm' -l 'mode' -x -a 'fast slow' -d 'speed'",
+				"complete -c 'my-prog' -s 'o' -l 'output' -r -F -d 'output file'",
+			},
+			skip: []string{"'completion'"},
+		},
+	} {
+		reset()
+		SetProgram("my-prog")
+		var (
+			color, alpha, beta bool
+			debug              string
+		)
+		BoolLong("verbose", 'v', "be verbose")
+		FlagLong(&color, "color", 0, "use color").Negatable()
+		StringLong("output", 'o', "", "output file", "file")
+		EnumLong("mode", 'm', []string{"slow", "fast"}, "", "speed")
+		IntLong("count", 'c', 0, "count")
+		FlagLong(&debug, "debug", 0, "debug level").SetOptional()
+		FlagLong(&alpha, "alpha", 'a', "alpha").SetGroup("greek")
+		FlagLong(&beta, "beta", 'b', "beta").SetGroup("greek")
+		AddCompletionOption()
+
+		var b bytes.Buffer
+		if err := CommandLine.WriteCompletion(&b, tt.shell); err != nil {
+			t.Errorf("%s: %v", tt.shell, err)
+			continue
+		}
+		script := b.String()
+		lines := map[string]bool{}
+		for _, line := range strings.Split(script, "\n") {
+			lines[line] = true
+		}
+		for _, line := range tt.want {
+			if !lines[line] {
+				t.Errorf("%s: missing line %q in:\n%s", tt.shell, line, script)
+			}
+		}
+		for _, str := range tt.skip {
+			if strings.Contains(script, str) {
+				t.Errorf("%s: unexpected %q in:\n%s", tt.shell, str, script)
+			}
+		}
+	}
+}
+
+func TestCompletionErrors(t *testing.T) {
+	reset()
+	SetProgram("test")
+	Bool('v', "be verbose")
+	var b bytes.Buffer
+	if err := CommandLine.WriteCompletion(&b, "csh"); err == nil {
+		t.Errorf("csh: did not get an error")
+	}
+	SetProgram("")
+	if err := CommandLine.WriteCompletion(&b, "bash"); err == nil {
+		t.Errorf("no program: did not get an error")
+	}
+}
+
+func TestCompletionOption(t *testing.T) {
+	reset()
+	defer func(w io.Writer) { stdout = w }(stdout)
+	var b bytes.Buffer
+	stdout = &b
+	exited := -1
+	defer func(fn func(int)) { exit = fn }(exit)
+	exit = func(code int) { exited = code }
+
+	Bool('v', "be verbose")
+	AddCompletionOption()
+	if usage := CommandLine.UsageLine(); usage != "[-v]" {
+		t.Errorf("got usage %q, want %q", usage, "[-v]")
+	}
+	parse([]string{"prog", "--completion=bash"})
+	if exited != 0 {
+		t.Errorf("got exit code %d, want 0", exited)
+	}
+	if !strings.Contains(b.String(), "complete -F _prog 'prog'") {
+		t.Errorf("did not write the bash script:\n%s", b.String())
+	}
+	if errorString != "" {
+		t.Errorf("unexpected error:\n%s", errorString)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+// RequiredIf requires o to be used if pred returns true for the option
+// other once parsing is done.  Pred is passed other even if it was not used,
+// so it sees the default value of other.
+func (o *option) RequiredIf(other Option, pred func(Option) bool) Option {
+	o.constraints = append(o.constraints, &constraint{kind: requiredIf, opt: o, opts: optionsOf([]Option{other}), pred: pred})
+	return o
+}
+
+// ForbiddenIf prohibits o from being used if pred returns true for the option
+// other once parsing is done.  Pred is passed other even if it was not used,
+// so it sees the default value of other.
+func (o *option) ForbiddenIf(other Option, pred func(Option) bool) Option {
+	o.constraints = append(o.constraints, &constraint{kind: forbiddenIf, opt: o, opts: optionsOf([]Option{other}), pred: pred})
+	return o
+}
+
+// HasValue returns a predicate, for RequiredIf and ForbiddenIf, that returns
+// true if the value of the option, as returned by its String method, is one of
+// values.  Option.Seen may be used as a predicate that returns true if the
+// option was seen.
+func HasValue(values ...string) func(Option) bool {
+	return func(o Option) bool {
+		v := o.String()
+		for _, value := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AddValidator calls AddValidator on the command line options.
+func AddValidator(fn func(s *Set) error) {
+	CommandLine.AddValidator(fn)
+}
+
+// AddValidator adds fn to the validators of s.  After the options have been
+// parsed and checked, Getopt calls each validator in the order they were
+// added.  A validator may inspect any of the options in s, such as with
+// Lookup, but should not change them.  If fn returns an error that is not an
+// *Error, Getopt returns it as an *Error with the ErrorCode ValidationFailed.
+// A validator that returns an *Error should set its Options to the options
+// involved.
+func (s *Set) AddValidator(fn func(s *Set) error) {
+	s.validators = append(s.validators, fn)
+}
+
+// validatorErrors returns the errors returned by the validators of s.
+func (s *Set) validatorErrors() []*Error {
+	var errs []*Error
+	for _, fn := range s.validators {
+		if err := fn(s); err != nil {
+			errs = append(errs, validationFailed(err))
+		}
+	}
+	return errs
+}
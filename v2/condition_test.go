@@ -0,0 +1,159 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestConditions(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		in    []string
+		codes []ErrorCode
+		errs  []string
+		names [][]string
+	}{
+		{
+			where: loc(),
+			in:    []string{"prog"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--mode=tls", "--cert=c"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--mode=plain"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-m", "mtls"},
+			codes: []ErrorCode{ConditionallyRequired},
+			errs:  []string{"option --cert is required by -m=mtls"},
+			names: [][]string{{"--cert", "-m"}},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--port=1", "--socket=/tmp/s"},
+			codes: []ErrorCode{ConditionallyForbidden},
+			errs:  []string{"option --port is not allowed with --socket=/tmp/s"},
+			names: [][]string{{"--port", "--socket"}},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--port=1"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "--low=5", "--high=3", "a", "b"},
+			codes: []ErrorCode{ValidationFailed, ValidationFailed},
+			errs:  []string{"--low is greater than --high", "too many files"},
+			names: [][]string{{"--low", "--high"}, nil},
+		},
+	} {
+		reset()
+		SetCollectErrors(true)
+		var mode, cert, socket string
+		var port, low, high int
+		modeOpt := FlagLong(&mode, "mode", 'm', "connection mode")
+		FlagLong(&cert, "cert", 0, "certificate file").RequiredIf(modeOpt, HasValue("tls", "mtls"))
+		socketOpt := FlagLong(&socket, "socket", 0, "unix socket")
+		FlagLong(&port, "port", 'p', "tcp port").ForbiddenIf(socketOpt, Option.Seen)
+		FlagLong(&low, "low", 0, "low limit")
+		FlagLong(&high, "high", 0, "high limit")
+		AddValidator(func(s *Set) error {
+			if low > high {
+				return &Error{
+					ErrorCode: ValidationFailed,
+					Options:   []Option{s.Lookup("low"), s.Lookup("high")},
+					Err:       errors.New("--low is greater than --high"),
+				}
+			}
+			return nil
+		})
+		AddValidator(func(s *Set) error {
+			if s.NArgs() > 1 {
+				return errors.New("too many files")
+			}
+			return nil
+		})
+		err := CommandLine.Getopt(tt.in, nil)
+		if len(tt.codes) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.where, err)
+			}
+			continue
+		}
+		errs, ok := err.(Errors)
+		if !ok {
+			t.Errorf("%s: got error %v (%T), want Errors", tt.where, err, err)
+			continue
+		}
+		if len(errs) != len(tt.codes) {
+			t.Errorf("%s: got %d errors, want %d: %v", tt.where, len(errs), len(tt.codes), errs)
+			continue
+		}
+		for x, e := range errs {
+			if e.ErrorCode != tt.codes[x] {
+				t.Errorf("%s: error %d: got code %v, want %v", tt.where, x, e.ErrorCode, tt.codes[x])
+			}
+			if e.Error() != tt.errs[x] {
+				t.Errorf("%s: error %d: got %q, want %q", tt.where, x, e.Error(), tt.errs[x])
+			}
+			var names []string
+			for _, o := range e.Options {
+				names = append(names, o.Name())
+			}
+			if badSlice(names, tt.names[x]) {
+				t.Errorf("%s: error %d: got options %q, want %q", tt.where, x, names, tt.names[x])
+			}
+		}
+	}
+}
+
+func TestConditionsUsage(t *testing.T) {
+	HelpColumn = 20
+	reset()
+	var mode, cert, socket string
+	var port, low, high int
+	modeOpt := FlagLong(&mode, "mode", 'm', "connection mode")
+	FlagLong(&cert, "cert", 0, "certificate file").RequiredIf(modeOpt, HasValue("tls", "mtls"))
+	socketOpt := FlagLong(&socket, "socket", 0, "unix socket")
+	FlagLong(&port, "port", 'p', "tcp port").ForbiddenIf(socketOpt, Option.Seen)
+	FlagLong(&low, "low", 0, "low limit")
+	FlagLong(&high, "high", 0, "high limit")
+	var b bytes.Buffer
+	CommandLine.PrintOptions(&b)
+	want := `     --cert=value  certificate file (required depending on --mode)
+     --high=value  high limit
+     --low=value   low limit
+ -m, --mode=value  connection mode
+ -p, --port=value  tcp port (forbidden depending on --socket)
+     --socket=value
+                   unix socket
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestValidatorCommandLine(t *testing.T) {
+	reset()
+	var v bool
+	Flag(&v, 'v')
+	AddValidator(func(s *Set) error {
+		if !s.IsSet('v') {
+			return errors.New("-v is required today")
+		}
+		return nil
+	})
+	parse([]string{"test"})
+	if s := checkError("test: -v is required today"); s != "" {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A ConfigFormat is the format of a configuration file.
+type ConfigFormat int
+
+const (
+	ConfigINI  = ConfigFormat(iota) // INI style key=value lines
+	ConfigJSON                      // a JSON object
+)
+
+// LoadConfigFile calls LoadConfigFile on the command line options.
+func LoadConfigFile(path string) error {
+	return CommandLine.LoadConfigFile(path)
+}
+
+// LoadConfigFile loads the configuration file path into s.  Files ending in
+// .json are in the ConfigJSON format, all other files are in the ConfigINI
+// format.  See LoadConfig for details.
+func (s *Set) LoadConfigFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	format := ConfigINI
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		format = ConfigJSON
+	}
+	return s.LoadConfig(f, path, format)
+}
+
+// LoadConfig calls LoadConfig on the command line options.
+func LoadConfig(r io.Reader, name string, format ConfigFormat) error {
+	return CommandLine.LoadConfig(r, name, format)
+}
+
+// LoadConfig sets the options of s from the configuration read from r in the
+// specified format.  Name is the name of the configuration, normally a file
+// name, and is used in errors.  Each key of the configuration is the long name
+// of an option and its value is passed to the option's Value.Set.
+//
+// The ConfigINI format consists of lines of the form "key = value".  A key
+// without a value sets a flag.  A value may be quoted with double quotes, in
+// which case Go escape sequences are recognized.  Blank lines and lines
+// starting with # or ; are ignored.  A line of the form "[section]" causes
+// the keys that follow to be prefixed with "section.".  A list option may be
+// repeated to append values.  An unquoted value of a list option is split at
+// commas, a quoted one is not.
+//
+// The ConfigJSON format is a JSON object.  Values may be strings, numbers,
+// booleans, or, for list options, arrays.  A null value is ignored.
+//
+// Options that were seen on the command line, were set from the environment
+// (see Option.Env), or are in a mutually exclusive group with such an option
+// are not changed.  An option set from a configuration satisfies Mandatory
+// and RequiredGroup, but its Seen and Count do not change.  To allow this,
+// LoadConfig is normally called before Getopt.  An option that is set from
+// a configuration and later is in conflict with an option in its mutually
+// exclusive group seen by Getopt is reset to its default value.
+//
+// LoadConfig returns an Error, with File and Line set, for unknown keys and
+// values that cannot be set.
+func (s *Set) LoadConfig(r io.Reader, name string, format ConfigFormat) error {
+	var entries []configEntry
+	var err error
+	switch format {
+	case ConfigINI:
+		entries, err = readINI(r, name)
+	case ConfigJSON:
+		entries, err = readJSON(r, name)
+	default:
+		return fmt.Errorf("unknown config format: %d", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	groups := map[string]bool{}
+	for _, opt := range s.options {
+		if opt.group != "" && (opt.Seen() || opt.envSet != "") {
+			groups[opt.group] = true
+		}
+	}
+	for _, e := range entries {
+		opt := s.longOptions[e.key]
+		if opt == nil {
+			return configError(unknownOption(e.key), name, e.line)
+		}
+		if opt.Seen() || opt.envSet != "" || (opt.group != "" && groups[opt.group]) {
+			continue
+		}
+		if p, ok := genericValue(opt.value).(*[]string); ok {
+			list, split := e.list, e.split
+			if list == nil {
+				list, split = []string{e.value}, []bool{!e.quoted}
+			}
+			*p = nil
+			for x, v := range list {
+				if split != nil && split[x] {
+					*p = append(*p, strings.Split(v, ",")...)
+				} else {
+					*p = append(*p, v)
+				}
+				if err := opt.validate(v); err != nil {
+					return configError(setError(opt, v, err), name, e.line)
+				}
+			}
+			opt.config = name
+			continue
+		}
+		if e.list == nil {
+			e.list = []string{e.value}
+		}
+		for _, value := range e.list {
+			if err := opt.setValue(value); err != nil {
+				return configError(setError(opt, value, err), name, e.line)
+			}
+		}
+		opt.config = name
+	}
+	return nil
+}
+
+// A configEntry is a single key and value read from a configuration.
+type configEntry struct {
+	key    string
+	value  string
+	quoted bool     // value was quoted
+	list   []string // set if the value is a list
+	split  []bool   // which values of list are comma separated lists
+	line   int
+}
+
+// configError returns err with the file and line set.
+func configError(err *Error, file string, line int) *Error {
+	err.File = file
+	err.Line = line
+	err.Err = fmt.Errorf("%s:%d: %v", file, line, err.Err)
+	return err
+}
+
+// readINI reads the entries of a ConfigINI configuration named name from r.
+// Repeated keys of list options are merged into a single entry.
+func readINI(r io.Reader, name string) ([]configEntry, error) {
+	var entries []configEntry
+	lists := map[string]int{} // index into entries of each key
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", line[0] == '#', line[0] == ';':
+			continue
+		case line[0] == '[':
+			if line[len(line)-1] != ']' {
+				return nil, &Error{ErrorCode: Invalid, File: name, Line: n, Err: fmt.Errorf("%s:%d: invalid section: %s", name, n, line)}
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section != "" {
+				section += "."
+			}
+			continue
+		}
+		e := configEntry{key: line, line: n}
+		if x := strings.Index(line, "="); x >= 0 {
+			e.key = strings.TrimSpace(line[:x])
+			e.value = strings.TrimSpace(line[x+1:])
+			switch {
+			case len(e.value) >= 2 && e.value[0] == '"' && e.value[len(e.value)-1] == '"':
+				v, err := strconv.Unquote(e.value)
+				if err != nil {
+					return nil, &Error{ErrorCode: Invalid, Name: e.key, File: name, Line: n, Err: fmt.Errorf("%s:%d: invalid quoted value: %s", name, n, e.value)}
+				}
+				e.value = v
+				e.quoted = true
+			case len(e.value) >= 2 && e.value[0] == '\'' && e.value[len(e.value)-1] == '\'':
+				e.value = e.value[1 : len(e.value)-1]
+				e.quoted = true
+			}
+		}
+		e.key = section + e.key
+		if x, ok := lists[e.key]; ok {
+			if entries[x].list == nil {
+				entries[x].list = []string{entries[x].value}
+				entries[x].split = []bool{!entries[x].quoted}
+			}
+			entries[x].list = append(entries[x].list, e.value)
+			entries[x].split = append(entries[x].split, !e.quoted)
+			continue
+		}
+		lists[e.key] = len(entries)
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readJSON reads the entries of a ConfigJSON configuration named name from r.
+func readJSON(r io.Reader, name string) ([]configEntry, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	jsonError := func(line int, err error) error {
+		return &Error{ErrorCode: Invalid, File: name, Line: line, Err: fmt.Errorf("%s:%d: %v", name, line, err)}
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, jsonError(1, fmt.Errorf("configuration is not a JSON object"))
+	}
+	var entries []configEntry
+	offset := 0
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, jsonError(0, err)
+		}
+		key := t.(string)
+		// Find the line of the key by searching for it from the end
+		// of the previous key.
+		line := 0
+		quoted, _ := json.Marshal(key)
+		if x := bytes.Index(data[offset:], quoted); x >= 0 {
+			offset += x + len(quoted)
+			line = bytes.Count(data[:offset], []byte("\n")) + 1
+		}
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			return nil, jsonError(line, err)
+		}
+		e := configEntry{key: key, line: line}
+		switch v := v.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			e.list = []string{}
+			for _, v := range v {
+				s, ok := jsonString(v)
+				if !ok {
+					return nil, jsonError(line, fmt.Errorf("invalid value for %s", key))
+				}
+				e.list = append(e.list, s)
+			}
+		default:
+			s, ok := jsonString(v)
+			if !ok {
+				return nil, jsonError(line, fmt.Errorf("invalid value for %s", key))
+			}
+			e.value = s
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// jsonString returns the JSON value v as a string.  It returns false if v is
+// not a string, number, or boolean.
+func jsonString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// ConfigOptions control how WriteConfig writes a configuration.
+type ConfigOptions struct {
+	// Defaults causes options that have their default value to be
+	// written as comments, rather than as values.  Comments are only
+	// written in the ConfigINI format, so in the ConfigJSON format
+	// these options are not written.
+	Defaults bool
+
+	// SkipUnchanged causes options that have their default value to not
+	// be written.  SkipUnchanged takes precedence over Defaults.
+	SkipUnchanged bool
+
+	// Help causes the help message of each option to be written as a
+	// comment above the option (ConfigINI format only).
+	Help bool
+}
+
+// WriteConfig calls WriteConfig on the command line options.
+func WriteConfig(w io.Writer, format ConfigFormat, opts ConfigOptions) error {
+	return CommandLine.WriteConfig(w, format, opts)
+}
+
+// WriteConfig writes the current values of the options in s to w as a
+// configuration in the specified format.  Each option with a long name is
+// written, in the same order as VisitAll, using the long name as the key and
+// the option's String as the value.  In the ConfigINI format each value of a
+// list option is written on its own line, and an empty list is written as a
+// comment.  Reading the configuration with LoadConfig sets the options to the
+// values they had when it was written, except that an empty list keeps its
+// default value.
+func (s *Set) WriteConfig(w io.Writer, format ConfigFormat, opts ConfigOptions) error {
+	type entry struct {
+		opt     *option
+		value   string
+		changed bool
+	}
+	var entries []entry
+	s.VisitAll(func(o Option) {
+		opt := o.(*option)
+		if opt.long == "" {
+			return
+		}
+		e := entry{opt: opt, value: opt.String(), changed: opt.String() != opt.defval}
+		if !e.changed && (opts.SkipUnchanged || (format == ConfigJSON && opts.Defaults)) {
+			return
+		}
+		entries = append(entries, e)
+	})
+
+	switch format {
+	case ConfigINI:
+		var buf bytes.Buffer
+		for x, e := range entries {
+			if opts.Help && e.opt.help != "" {
+				if x > 0 {
+					fmt.Fprintln(&buf)
+				}
+				for _, line := range strings.Split(strings.TrimSpace(e.opt.help), "\n") {
+					fmt.Fprintf(&buf, "# %s\n", line)
+				}
+			}
+			comment := ""
+			if !e.changed && opts.Defaults {
+				comment = "# "
+			}
+			p, ok := genericValue(e.opt.value).(*[]string)
+			switch {
+			case !ok:
+				fmt.Fprintf(&buf, "%s%s = %s\n", comment, e.opt.long, iniValue(e.value))
+			case len(*p) == 0:
+				// An empty list cannot be read back.
+				fmt.Fprintf(&buf, "# %s =\n", e.opt.long)
+			default:
+				for _, v := range *p {
+					if strings.Contains(v, ",") {
+						v = strconv.Quote(v)
+					} else {
+						v = iniValue(v)
+					}
+					fmt.Fprintf(&buf, "%s%s = %s\n", comment, e.opt.long, v)
+				}
+			}
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	case ConfigJSON:
+		m := map[string]interface{}{}
+		for _, e := range entries {
+			m[e.opt.long] = jsonValue(e.opt, e.value)
+		}
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	return fmt.Errorf("unknown config format: %d", format)
+}
+
+// iniValue returns value quoted, if needed, so readINI reads it as value.
+func iniValue(value string) string {
+	if value == "" {
+		return value
+	}
+	if value != strings.TrimSpace(value) || strconv.Quote(value) != `"`+value+`"` {
+		return strconv.Quote(value)
+	}
+	switch value[0] {
+	case '"', '\'':
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// jsonValue returns value, the value of opt, as the type it should be
+// written as in JSON.
+func jsonValue(opt *option, value string) interface{} {
+	switch p := genericValue(opt.value).(type) {
+	case *[]string:
+		return append([]string{}, *p...)
+	case *bool:
+		return *p
+	case *int, *int8, *int16, *int32, *int64,
+		*uint, *uint8, *uint16, *uint32, *uint64,
+		*float32, *float64:
+		if json.Valid([]byte(value)) {
+			return json.Number(value)
+		}
+	}
+	return value
+}
@@ -0,0 +1,321 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+var configTests = []struct {
+	where   string
+	format  ConfigFormat
+	config  string
+	in      []string
+	timeout time.Duration
+	verbose bool
+	name    string
+	list    []string
+	err     string
+	line    int
+}{
+	{
+		where:   loc(),
+		format:  ConfigINI,
+		config:  "# comment\ntimeout = 5s\n; comment\n\nverbose\nname = \"fred flintstone\"\nlist = a,b\nlist=c\n",
+		in:      []string{"test"},
+		timeout: 5 * time.Second,
+		verbose: true,
+		name:    "fred flintstone",
+		list:    []string{"a", "b", "c"},
+	},
+	{
+		where:   loc(),
+		format:  ConfigINI,
+		config:  "[db]\nhost = localhost\n",
+		in:      []string{"test"},
+		timeout: time.Second,
+		name:    "localhost",
+	},
+	{
+		where:   loc(),
+		format:  ConfigINI,
+		config:  "timeout = 5s\nlist = a\n",
+		in:      []string{"test", "--timeout=3s", "--list=x"},
+		timeout: 3 * time.Second,
+		list:    []string{"x"},
+	},
+	{
+		where:   loc(),
+		format:  ConfigINI,
+		config:  "timeout = 5s\n\nbogus = 1\n",
+		in:      []string{"test"},
+		timeout: 5 * time.Second,
+		err:     "test.conf:3: unknown option: bogus",
+		line:    3,
+	},
+	{
+		where:   loc(),
+		format:  ConfigINI,
+		config:  "timeout = soon\n",
+		in:      []string{"test"},
+		timeout: time.Second,
+		err:     "test.conf:1: time: invalid duration",
+		line:    1,
+	},
+	{
+		where:   loc(),
+		format:  ConfigJSON,
+		config:  "{\n  \"timeout\": \"5s\",\n  \"verbose\": true,\n  \"list\": [\"a,b\", \"c\"],\n  \"name\": null\n}\n",
+		in:      []string{"test"},
+		timeout: 5 * time.Second,
+		verbose: true,
+		list:    []string{"a,b", "c"},
+	},
+	{
+		where:   loc(),
+		format:  ConfigJSON,
+		config:  "{\n  \"timeout\": \"5s\",\n\n  \"bogus\": 1\n}\n",
+		in:      []string{"test"},
+		timeout: 5 * time.Second,
+		err:     "test.conf:4: unknown option: bogus",
+		line:    4,
+	},
+	{
+		where:   loc(),
+		format:  ConfigJSON,
+		config:  "[1]",
+		in:      []string{"test"},
+		timeout: time.Second,
+		err:     "test.conf:1: configuration is not a JSON object",
+		line:    1,
+	},
+}
+
+func TestLoadConfig(t *testing.T) {
+	for _, tt := range configTests {
+		reset()
+		timeout := time.Second
+		var verbose bool
+		var name string
+		var list []string
+		FlagLong(&timeout, "timeout", 't')
+		FlagLong(&verbose, "verbose", 'v')
+		FlagLong(&name, "name", 0)
+		FlagLong(&name, "db.host", 0)
+		FlagLong(&list, "list", 0)
+
+		err := LoadConfig(strings.NewReader(tt.config), "test.conf", tt.format)
+		switch {
+		case err == nil && tt.err != "":
+			t.Errorf("%s: did not get error %q", tt.where, tt.err)
+		case err != nil && !strings.HasPrefix(err.Error(), tt.err):
+			t.Errorf("%s: got error %q, want %q", tt.where, err, tt.err)
+		case err != nil:
+			e, ok := err.(*Error)
+			if !ok || e.File != "test.conf" || e.Line != tt.line {
+				t.Errorf("%s: got error %#v, want test.conf:%d", tt.where, err, tt.line)
+			}
+		}
+		parse(tt.in)
+		if errorString != "" {
+			t.Errorf("%s: unexpected error %s", tt.where, errorString)
+		}
+		if timeout != tt.timeout || verbose != tt.verbose || name != tt.name {
+			t.Errorf("%s: got %v %v %q, want %v %v %q", tt.where, timeout, verbose, name, tt.timeout, tt.verbose, tt.name)
+		}
+		if badSlice(list, tt.list) {
+			t.Errorf("%s: got list %q, want %q", tt.where, list, tt.list)
+		}
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	reset()
+	var timeout time.Duration
+	var a, b bool
+	FlagLong(&timeout, "timeout", 't').Mandatory()
+	FlagLong(&a, "a", 0).SetGroup("ab")
+	FlagLong(&b, "b", 0).SetGroup("ab")
+	RequiredGroup("ab")
+
+	// The configuration satisfies Mandatory and RequiredGroup.
+	if err := LoadConfig(strings.NewReader("timeout=1s\na=true"), "c", ConfigINI); err != nil {
+		t.Fatal(err)
+	}
+	parse([]string{"test"})
+	if errorString != "" {
+		t.Fatalf("unexpected error %s", errorString)
+	}
+	if !a || timeout != time.Second {
+		t.Errorf("got %v %v, want true 1s", a, timeout)
+	}
+
+	// Seeing b on the command line overrides a from the configuration.
+	parse([]string{"test", "--b"})
+	if errorString != "" {
+		t.Fatalf("unexpected error %s", errorString)
+	}
+	if a || !b {
+		t.Errorf("got a=%v b=%v, want a=false b=true", a, b)
+	}
+
+	// Loading after parsing does not change options that were seen.
+	if err := LoadConfig(strings.NewReader("a\ntimeout=2s"), "c", ConfigINI); err != nil {
+		t.Fatal(err)
+	}
+	if a || timeout != 2*time.Second {
+		t.Errorf("got %v %v, want false 2s", a, timeout)
+	}
+}
+
+func TestWriteConfig(t *testing.T) {
+	for _, tt := range []struct {
+		where  string
+		format ConfigFormat
+		opts   ConfigOptions
+		want   string
+	}{
+		{
+			where:  loc(),
+			format: ConfigINI,
+			want: `
+count = 3
+list = a
+list = b
+name = " fred \"f\" "
+# none =
+ratio = 0.5
+tag = "x,y"
+tag = z
+timeout = 1s
+verbose = true
+`[1:],
+		},
+		{
+			where:  loc(),
+			format: ConfigINI,
+			opts:   ConfigOptions{Defaults: true, Help: true},
+			want: `
+# the count
+count = 3
+list = a
+list = b
+
+# the name
+name = " fred \"f\" "
+# none =
+# ratio = 0.5
+tag = "x,y"
+tag = z
+
+# the timeout
+# timeout = 1s
+verbose = true
+`[1:],
+		},
+		{
+			where:  loc(),
+			format: ConfigINI,
+			opts:   ConfigOptions{Defaults: true, SkipUnchanged: true},
+			want: `
+count = 3
+list = a
+list = b
+name = " fred \"f\" "
+tag = "x,y"
+tag = z
+verbose = true
+`[1:],
+		},
+		{
+			where:  loc(),
+			format: ConfigJSON,
+			want: `
+{
+  "count": 3,
+  "list": [
+    "a",
+    "b"
+  ],
+  "name": " fred \"f\" ",
+  "none": [],
+  "ratio": 0.5,
+  "tag": [
+    "x,y",
+    "z"
+  ],
+  "timeout": "1s",
+  "verbose": true
+}
+`[1:],
+		},
+		{
+			where:  loc(),
+			format: ConfigJSON,
+			opts:   ConfigOptions{Defaults: true},
+			want: `
+{
+  "count": 3,
+  "list": [
+    "a",
+    "b"
+  ],
+  "name": " fred \"f\" ",
+  "tag": [
+    "x,y",
+    "z"
+  ],
+  "verbose": true
+}
+`[1:],
+		},
+	} {
+		reset()
+		count := 0
+		var list, tags, none []string
+		var name string
+		ratio := 0.5
+		timeout := time.Second
+		var verbose bool
+		var short bool
+		FlagLong(&count, "count", 'c', "the count")
+		FlagLong(&list, "list", 'l')
+		FlagLong(&tags, "tag", 0)
+		FlagLong(&none, "none", 0)
+		FlagLong(&name, "name", 'n', "the name")
+		FlagLong(&ratio, "ratio", 0)
+		FlagLong(&timeout, "timeout", 't', "the timeout")
+		FlagLong(&verbose, "verbose", 'v')
+		Flag(&short, 's')
+		parse([]string{"test", "-c3", "-l", "a,b", "--name", ` fred "f" `, "-v", "-s"})
+		if errorString != "" {
+			t.Fatal(errorString)
+		}
+		tags = []string{"x,y", "z"}
+
+		var buf bytes.Buffer
+		if err := WriteConfig(&buf, tt.format, tt.opts); err != nil {
+			t.Fatalf("%s: %v", tt.where, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.where, got, tt.want)
+		}
+
+		// Reading the configuration back must restore the values.
+		Reset()
+		if err := LoadConfig(&buf, "test", tt.format); err != nil {
+			t.Fatalf("%s: %v", tt.where, err)
+		}
+		if badSlice(tags, []string{"x,y", "z"}) {
+			t.Errorf("%s: read back tags %q", tt.where, tags)
+		}
+		if count != 3 || badSlice(list, []string{"a", "b"}) || name != ` fred "f" ` || ratio != 0.5 || timeout != time.Second || !verbose {
+			t.Errorf("%s: read back %d %q %q %v %v %v", tt.where, count, list, name, ratio, timeout, verbose)
+		}
+	}
+}
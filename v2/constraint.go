@@ -0,0 +1,231 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A constraintKind is the kind of a constraint.
+type constraintKind int
+
+const (
+	requires    = constraintKind(iota) // opt requires each of opts
+	conflicts                          // opt conflicts with each of opts
+	allOrNone                          // all or none of opts
+	atLeastOne                         // at least one of opts
+	atMost                             // at most n of opts
+	requiredIf                         // opt is required if pred(opts[0])
+	forbiddenIf                        // opt is forbidden if pred(opts[0])
+)
+
+// A constraint is a rule about which options may be used together.  An
+// option is used if it is present (seen, or set from the environment or a
+// configuration file).
+type constraint struct {
+	kind constraintKind
+	opt  *option   // the option of a requires or conflicts constraint
+	opts []*option // the other options of the constraint
+	n    int       // the limit of an atMost constraint
+
+	// pred is the predicate of a requiredIf or forbiddenIf constraint.
+	pred func(Option) bool
+}
+
+// optionsOf returns opts as options.
+func optionsOf(opts []Option) []*option {
+	list := make([]*option, len(opts))
+	for x, o := range opts {
+		list[x] = o.(*option)
+	}
+	return list
+}
+
+// Requires requires each of opts to be used when o is used.
+func (o *option) Requires(opts ...Option) Option {
+	o.constraints = append(o.constraints, &constraint{kind: requires, opt: o, opts: optionsOf(opts)})
+	return o
+}
+
+// ConflictsWith prohibits each of opts from being used along with o.
+func (o *option) ConflictsWith(opts ...Option) Option {
+	o.constraints = append(o.constraints, &constraint{kind: conflicts, opt: o, opts: optionsOf(opts)})
+	return o
+}
+
+// AllOrNone calls AllOrNone on the command line options.
+func AllOrNone(opts ...Option) {
+	CommandLine.AllOrNone(opts...)
+}
+
+// AllOrNone requires either all or none of opts to be used, such as a user
+// name and a password.
+func (s *Set) AllOrNone(opts ...Option) {
+	s.constraints = append(s.constraints, &constraint{kind: allOrNone, opts: optionsOf(opts)})
+}
+
+// AtLeastOne calls AtLeastOne on the command line options.
+func AtLeastOne(opts ...Option) {
+	CommandLine.AtLeastOne(opts...)
+}
+
+// AtLeastOne requires at least one of opts to be used.  Unlike RequiredGroup
+// more than one may be used.
+func (s *Set) AtLeastOne(opts ...Option) {
+	s.constraints = append(s.constraints, &constraint{kind: atLeastOne, opts: optionsOf(opts)})
+}
+
+// AtMost calls AtMost on the command line options.
+func AtMost(n int, opts ...Option) {
+	CommandLine.AtMost(n, opts...)
+}
+
+// AtMost allows no more than n of opts to be used.  AtMost(1, ...) is the
+// same as placing opts in a mutually exclusive group (see SetGroup).
+func (s *Set) AtMost(n int, opts ...Option) {
+	if n < 0 {
+		fmt.Fprintf(stderr, "%s: invalid limit %d for AtMost\n", s.program, n)
+		exit(1)
+	}
+	s.constraints = append(s.constraints, &constraint{kind: atMost, opts: optionsOf(opts), n: n})
+}
+
+// constraintErrors returns the errors for the constraints of s that are not
+// met, those of the options first.
+func (s *Set) constraintErrors() []*Error {
+	var errs []*Error
+	for _, opt := range s.options {
+		for _, c := range opt.constraints {
+			errs = c.check(errs)
+		}
+	}
+	for _, c := range s.constraints {
+		errs = c.check(errs)
+	}
+	return errs
+}
+
+// check appends an error to errs for each way c is not met and returns errs.
+func (c *constraint) check(errs []*Error) []*Error {
+	switch c.kind {
+	case requires:
+		if !c.opt.present() {
+			break
+		}
+		for _, o := range c.opts {
+			if !o.present() {
+				errs = append(errs, missingRequirement(c.opt, o))
+			}
+		}
+	case conflicts:
+		if !c.opt.present() {
+			break
+		}
+		for _, o := range c.opts {
+			if o.present() {
+				errs = append(errs, conflictingOptions(c.opt, o))
+			}
+		}
+	case allOrNone:
+		if n := c.present(); n > 0 && n < len(c.opts) {
+			errs = append(errs, incompleteOptions(c.list()))
+		}
+	case atLeastOne:
+		if c.present() == 0 {
+			errs = append(errs, tooFewOptions(c.list()))
+		}
+	case atMost:
+		if c.present() > c.n {
+			errs = append(errs, tooManyOptions(c.n, c.list()))
+		}
+	case requiredIf:
+		if !c.opt.present() && c.pred(c.opts[0]) {
+			errs = append(errs, conditionallyRequired(c.opt, c.opts[0]))
+		}
+	case forbiddenIf:
+		if c.opt.present() && c.pred(c.opts[0]) {
+			errs = append(errs, conditionallyForbidden(c.opt, c.opts[0]))
+		}
+	}
+	return errs
+}
+
+// present returns the number of the options of c that are present.
+func (c *constraint) present() int {
+	n := 0
+	for _, o := range c.opts {
+		if o.present() {
+			n++
+		}
+	}
+	return n
+}
+
+// list returns the options of c as Options.
+func (c *constraint) list() []Option {
+	opts := make([]Option, len(c.opts))
+	for x, o := range c.opts {
+		opts[x] = o
+	}
+	return opts
+}
+
+// constraintHelp returns the description of the constraints opt is part of,
+// for the help message of opt.
+func (s *Set) constraintHelp(opt *option) string {
+	var help []string
+	for _, c := range opt.constraints {
+		switch c.kind {
+		case requires:
+			help = append(help, "(requires "+s.helpNames(c.opts)+")")
+		case conflicts:
+			help = append(help, "(conflicts with "+s.helpNames(c.opts)+")")
+		case requiredIf:
+			help = append(help, "(required depending on "+s.helpNames(c.opts)+")")
+		case forbiddenIf:
+			help = append(help, "(forbidden depending on "+s.helpNames(c.opts)+")")
+		}
+	}
+	for _, c := range s.constraints {
+		if !c.has(opt) {
+			continue
+		}
+		names := s.helpNames(c.opts)
+		switch c.kind {
+		case allOrNone:
+			help = append(help, "(all or none of "+names+")")
+		case atLeastOne:
+			help = append(help, "(at least one of "+names+")")
+		case atMost:
+			help = append(help, fmt.Sprintf("(at most %d of %s)", c.n, names))
+		}
+	}
+	return strings.Join(help, " ")
+}
+
+// has returns true if opt is one of the options of c.
+func (c *constraint) has(opt *option) bool {
+	for _, o := range c.opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// helpNames returns the names of opts as displayed in help messages, the
+// long name if there is one and otherwise the short name.
+func (s *Set) helpNames(opts []*option) string {
+	names := make([]string, len(opts))
+	for x, o := range opts {
+		if o.long != "" {
+			names[x] = s.longDash() + o.long
+		} else {
+			names[x] = "-" + string(o.short)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,219 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		in    []string
+		codes []ErrorCode
+		errs  []string
+		names [][]string
+	}{
+		{
+			where: loc(),
+			in:    []string{"prog", "-a"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-ab", "--cert=c", "--key=k", "-v", "-u", "me", "--password=pw"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-a", "--cert=c"},
+			codes: []ErrorCode{MissingRequirement},
+			errs:  []string{"option --cert requires --key"},
+			names: [][]string{{"--cert", "--key"}},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-a", "--key=k"},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-a", "-q", "--verbose"},
+			codes: []ErrorCode{ConflictingOptions},
+			errs:  []string{"option -q conflicts with --verbose"},
+			names: [][]string{{"-q", "--verbose"}},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-a", "--password=pw"},
+			codes: []ErrorCode{IncompleteOptions},
+			errs:  []string{"either all or none of the following options must be specified: -u, --password"},
+			names: [][]string{{"-u", "--password"}},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog"},
+			codes: []ErrorCode{TooFewOptions},
+			errs:  []string{"at least one of the following options must be specified: -a, -b, -c"},
+			names: [][]string{{"-a", "-b", "-c"}},
+		},
+		{
+			where: loc(),
+			in:    []string{"prog", "-abc"},
+			codes: []ErrorCode{TooManyOptions},
+			errs:  []string{"at most 2 of the following options may be specified: -a, -b, -c"},
+			names: [][]string{{"-a", "-b", "-c"}},
+		},
+	} {
+		reset()
+		var cert, key, user, password string
+		var quiet, verbose, a, b, c bool
+		certOpt := FlagLong(&cert, "cert", 0, "certificate file")
+		keyOpt := FlagLong(&key, "key", 0, "key file")
+		certOpt.Requires(keyOpt)
+		verboseOpt := FlagLong(&verbose, "verbose", 'v', "be verbose")
+		FlagLong(&quiet, "quiet", 'q', "be quiet").ConflictsWith(verboseOpt)
+		AllOrNone(
+			FlagLong(&user, "user", 'u', "user name"),
+			FlagLong(&password, "password", 0, "password"),
+		)
+		aOpt := Flag(&a, 'a', "mode a")
+		bOpt := Flag(&b, 'b', "mode b")
+		cOpt := Flag(&c, 'c', "mode c")
+		AtLeastOne(aOpt, bOpt, cOpt)
+		AtMost(2, aOpt, bOpt, cOpt)
+		SetCollectErrors(true)
+		err := CommandLine.Getopt(tt.in, nil)
+		if len(tt.codes) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.where, err)
+			}
+			continue
+		}
+		errs, ok := err.(Errors)
+		if !ok {
+			t.Errorf("%s: got error %v (%T), want Errors", tt.where, err, err)
+			continue
+		}
+		if len(errs) != len(tt.codes) {
+			t.Errorf("%s: got %d errors, want %d: %v", tt.where, len(errs), len(tt.codes), errs)
+			continue
+		}
+		for x, e := range errs {
+			if e.ErrorCode != tt.codes[x] {
+				t.Errorf("%s: error %d: got code %v, want %v", tt.where, x, e.ErrorCode, tt.codes[x])
+			}
+			if e.Error() != tt.errs[x] {
+				t.Errorf("%s: error %d: got %q, want %q", tt.where, x, e.Error(), tt.errs[x])
+			}
+			var names []string
+			for _, o := range e.Options {
+				names = append(names, o.Name())
+			}
+			if badSlice(names, tt.names[x]) {
+				t.Errorf("%s: error %d: got options %q, want %q", tt.where, x, names, tt.names[x])
+			}
+		}
+	}
+}
+
+func TestConstraintsAll(t *testing.T) {
+	reset()
+	var cert, key, user, password string
+	var quiet, verbose, a, b, c bool
+	certOpt := FlagLong(&cert, "cert", 0, "certificate file")
+	keyOpt := FlagLong(&key, "key", 0, "key file")
+	certOpt.Requires(keyOpt)
+	verboseOpt := FlagLong(&verbose, "verbose", 'v', "be verbose")
+	FlagLong(&quiet, "quiet", 'q', "be quiet").ConflictsWith(verboseOpt)
+	AllOrNone(
+		FlagLong(&user, "user", 'u', "user name"),
+		FlagLong(&password, "password", 0, "password"),
+	)
+	aOpt := Flag(&a, 'a', "mode a")
+	bOpt := Flag(&b, 'b', "mode b")
+	cOpt := Flag(&c, 'c', "mode c")
+	AtLeastOne(aOpt, bOpt, cOpt)
+	AtMost(2, aOpt, bOpt, cOpt)
+	SetCollectErrors(true)
+	err := CommandLine.Getopt([]string{"prog", "--cert=c", "-q", "-v", "-u", "me"}, nil)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("got error %v (%T), want Errors", err, err)
+	}
+	want := []ErrorCode{MissingRequirement, ConflictingOptions, IncompleteOptions, TooFewOptions}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %v", errs, want)
+	}
+	for x, e := range errs {
+		if e.ErrorCode != want[x] {
+			t.Errorf("error %d: got %v, want %v", x, e.ErrorCode, want[x])
+		}
+	}
+
+	// Without collecting errors only the first is returned.
+	reset()
+	FlagLong(&cert, "cert", 0, "certificate file").Requires(FlagLong(&key, "key", 0, "key file"))
+	FlagLong(&quiet, "quiet", 'q', "be quiet").ConflictsWith(FlagLong(&verbose, "verbose", 'v', "be verbose"))
+	err = CommandLine.Getopt([]string{"prog", "--cert=c", "-q", "-v"}, nil)
+	if e, ok := err.(*Error); !ok || e.ErrorCode != MissingRequirement {
+		t.Errorf("got error %v, want a %v error", err, MissingRequirement)
+	}
+}
+
+func TestConstraintsUsage(t *testing.T) {
+	HelpColumn = 20
+	reset()
+	var cert, key, user, password string
+	var quiet, verbose, a, b, c bool
+	certOpt := FlagLong(&cert, "cert", 0, "certificate file")
+	keyOpt := FlagLong(&key, "key", 0, "key file")
+	certOpt.Requires(keyOpt)
+	verboseOpt := FlagLong(&verbose, "verbose", 'v', "be verbose")
+	FlagLong(&quiet, "quiet", 'q', "be quiet").ConflictsWith(verboseOpt)
+	AllOrNone(
+		FlagLong(&user, "user", 'u', "user name"),
+		FlagLong(&password, "password", 0, "password"),
+	)
+	aOpt := Flag(&a, 'a', "mode a")
+	bOpt := Flag(&b, 'b', "mode b")
+	cOpt := Flag(&c, 'c', "mode c")
+	AtLeastOne(aOpt, bOpt, cOpt)
+	AtMost(2, aOpt, bOpt, cOpt)
+	var buf bytes.Buffer
+	CommandLine.PrintOptions(&buf)
+	want := ` -a                mode a (at least one of -a, -b, -c) (at most 2 of -a, -b,
+                   -c)
+ -b                mode b (at least one of -a, -b, -c) (at most 2 of -a, -b,
+                   -c)
+ -c                mode c (at least one of -a, -b, -c) (at most 2 of -a, -b,
+                   -c)
+     --cert=value  certificate file (requires --key)
+     --key=value   key file
+     --password=value
+                   password (all or none of --user, --password)
+ -q, --quiet       be quiet (conflicts with --verbose)
+ -u, --user=value  user name (all or none of --user, --password)
+ -v, --verbose     be verbose
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConstraintsCommandLine(t *testing.T) {
+	reset()
+	var a, b bool
+	AtMost(1, Flag(&a, 'a'), Flag(&b, 'b'))
+	AtLeastOne(Lookup('a'), Lookup('b'))
+	parse([]string{"test", "-ab"})
+	if s := checkError("test: at most 1 of the following options may be specified: -a, -b"); s != "" {
+		t.Error(s)
+	}
+	reset()
+	AllOrNone(Flag(&a, 'a'), Flag(&b, 'b'))
+	parse([]string{"test", "-b"})
+	if s := checkError("test: either all or none of the following options must be specified: -a, -b"); s != "" {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"io"
+)
+
+// A deprecation records that an option is deprecated.
+type deprecation struct {
+	msg         string  // why the option is deprecated
+	replacement *option // the option that receives the value, if not nil
+	warned      bool    // true once the warning has been issued
+}
+
+func (o *option) Hidden() Option { o.hidden = true; return o }
+
+func (o *option) Deprecated(msg string, replacement ...Option) Option {
+	o.deprecation = &deprecation{msg: msg}
+	switch len(replacement) {
+	case 0:
+	case 1:
+		o.deprecation.replacement = replacement[0].(*option)
+	default:
+		fmt.Fprintf(stderr, "%s: too many replacements for deprecated option %s\n", o.where, o.Name())
+		exit(1)
+	}
+	return o
+}
+
+// forward returns the option that receives the values of o.  It warns that o
+// is deprecated, if it is.
+func (p *Parser) forward(o *option) *option {
+	d := o.deprecation
+	if d == nil || p.dryRun {
+		return o
+	}
+	if !d.warned {
+		d.warned = true
+		p.s.warnDeprecated(o, d.msg)
+	}
+	if d.replacement != nil {
+		return d.replacement
+	}
+	return o
+}
+
+// warnDeprecated writes a warning that o is deprecated to the output of s and
+// calls the deprecation hook of s, if any.
+func (s *Set) warnDeprecated(o *option, msg string) {
+	warning := fmt.Sprintf("%s: option %s is deprecated", s.program, o.Name())
+	if msg != "" {
+		warning += ": " + msg
+	}
+	fmt.Fprintln(s.writer(), warning)
+	if s.deprecationHook != nil {
+		s.deprecationHook(o, msg)
+	}
+}
+
+// SetDeprecationHook calls SetDeprecationHook on the command line options.
+func SetDeprecationHook(fn func(o Option, msg string)) {
+	CommandLine.SetDeprecationHook(fn)
+}
+
+// SetDeprecationHook sets fn to be called, in addition to the warning written
+// to the output of s, the first time each deprecated option (see
+// Option.Deprecated) is used.  Msg is the message passed to Deprecated.
+func (s *Set) SetDeprecationHook(fn func(o Option, msg string)) {
+	s.deprecationHook = fn
+}
+
+// SetOutput calls SetOutput on the command line options.
+func SetOutput(w io.Writer) {
+	CommandLine.SetOutput(w)
+}
+
+// SetOutput sets the writer used for the usage message and errors displayed
+// by Parse and for warnings about deprecated options.  If w is nil, standard
+// error is used.
+func (s *Set) SetOutput(w io.Writer) {
+	s.output = w
+}
+
+// writer returns the output of s.
+func (s *Set) writer() io.Writer {
+	if s.output == nil {
+		return stderr
+	}
+	return s.output
+}
+
+// PrintUsageAll calls PrintUsageAll on the command line options.
+func PrintUsageAll(w io.Writer) {
+	CommandLine.PrintUsageAll(w)
+}
+
+// PrintUsageAll prints the usage message of s to w, as PrintUsage does, but
+// including the hidden options.  It is intended for a --help-all option.
+func (s *Set) PrintUsageAll(w io.Writer) {
+	s.showHidden = true
+	defer func() { s.showHidden = false }()
+	s.PrintUsage(w)
+}
+
+// hides returns true if s does not display opt.
+func (s *Set) hides(opt *option) bool {
+	return opt.hidden && !s.showHidden
+}
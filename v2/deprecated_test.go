@@ -0,0 +1,175 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHidden(t *testing.T) {
+	HelpColumn = 20
+	s := New()
+	s.SetProgram("prog")
+	var debug bool
+	var trace string
+	s.BoolLong("verbose", 'v', "be verbose")
+	s.FlagLong(&debug, "debug", 'd', "debug").Hidden()
+	s.FlagLong(&trace, "trace", 0, "trace file").Hidden()
+
+	if err := s.Getopt([]string{"prog", "-d", "--trace=x"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !debug || trace != "x" {
+		t.Errorf("hidden options not set: %v %q", debug, trace)
+	}
+
+	var b bytes.Buffer
+	s.PrintUsage(&b)
+	want := `Usage: prog [-v] [parameters ...]
+ -v, --verbose  be verbose
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	b.Reset()
+	s.PrintUsageAll(&b)
+	want = `Usage: prog [-dv] [--trace value] [parameters ...]
+ -d, --debug        debug
+     --trace=value  trace file
+ -v, --verbose      be verbose
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	b.Reset()
+	s.PrintUsage(&b)
+	if strings.Contains(b.String(), "debug") {
+		t.Errorf("PrintUsageAll left hidden options visible")
+	}
+
+	if got := s.Complete([]string{"-"}); badSlice(got, []string{"--verbose", "-v"}) {
+		t.Errorf("got completions %q", got)
+	}
+	b.Reset()
+	s.WriteManPage(&b, nil)
+	if strings.Contains(b.String(), "debug") {
+		t.Errorf("hidden option in man page:\n%s", b.String())
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		args  []string
+		name  string
+		out   string
+		err   string
+	}{
+		{
+			where: loc(),
+			args:  []string{"prog", "--old-name=a", "--old-name", "b"},
+			name:  "--new-name",
+			out:   "b",
+			err:   "prog: option --old-name is deprecated: use --new-name\n",
+		},
+		{
+			where: loc(),
+			args:  []string{"prog", "-o", "a"},
+			name:  "--new-name",
+			out:   "a",
+			err:   "prog: option -o is deprecated: use --new-name\n",
+		},
+		{
+			where: loc(),
+			args:  []string{"prog", "--new-name=a"},
+			name:  "--new-name",
+			out:   "a",
+		},
+		{
+			where: loc(),
+			args:  []string{"prog", "--legacy"},
+			err:   "prog: option --legacy is deprecated\n",
+		},
+	} {
+		var b bytes.Buffer
+		var hooked []string
+		s := New()
+		s.SetOutput(&b)
+		s.SetDeprecationHook(func(o Option, msg string) {
+			hooked = append(hooked, o.Name()+": "+msg)
+		})
+		var legacy bool
+		var old string
+		newName := s.StringLong("new-name", 0, "", "the new name")
+		s.FlagLong(&old, "old-name", 'o', "the old name").Deprecated("use --new-name", s.Lookup("new-name"))
+		s.FlagLong(&legacy, "legacy", 0, "").Deprecated("")
+
+		var names []string
+		err := s.Getopt(tt.args, func(o Option) bool {
+			names = append(names, o.Name())
+			return true
+		})
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+		if *newName != tt.out {
+			t.Errorf("%s: got %q, want %q", tt.where, *newName, tt.out)
+		}
+		if old != "" {
+			t.Errorf("%s: deprecated option set to %q", tt.where, old)
+		}
+		if tt.name != "" && names[0] != tt.name {
+			t.Errorf("%s: got name %q, want %q", tt.where, names[0], tt.name)
+		}
+		if got := b.String(); got != tt.err {
+			t.Errorf("%s: got warning %q, want %q", tt.where, got, tt.err)
+		}
+		if tt.err != "" && len(hooked) != 1 {
+			t.Errorf("%s: hook called %d times", tt.where, len(hooked))
+		}
+	}
+}
+
+func TestDeprecatedUsage(t *testing.T) {
+	HelpColumn = 20
+	s := New()
+	s.SetProgram("prog")
+	var legacy bool
+	s.FlagLong(&legacy, "legacy", 'l', "").Deprecated("")
+	var b bytes.Buffer
+	s.PrintOptions(&b)
+	want := " -l, --legacy  (deprecated)\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDeprecatedJSON(t *testing.T) {
+	s := New()
+	var old, name string
+	s.FlagLong(&old, "old-name", 0, "").Deprecated("renamed", s.FlagLong(&name, "new-name", 0, ""))
+	var b bytes.Buffer
+	if err := s.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	s.SetOutput(&out)
+	if err := s.Getopt([]string{"prog", "--old-name=x"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Lookup("new-name").String(); got != "x" {
+		t.Errorf("got %q, want %q", got, "x")
+	}
+	if got, want := out.String(), "prog: option --old-name is deprecated: renamed\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
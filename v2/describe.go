@@ -0,0 +1,295 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Description is a description of a Set, as written by WriteJSON.
+type Description struct {
+	Program        string                `json:"program,omitempty"`
+	Parameters     string                `json:"parameters,omitempty"`
+	RequiredGroups []string              `json:"required_groups,omitempty"`
+	Sections       []*SectionDescription `json:"sections,omitempty"`
+	Options        []*OptionDescription  `json:"options"`
+}
+
+// A SectionDescription is a description of a display section (see
+// Set.AddSection) in a Description.
+type SectionDescription struct {
+	Name  string `json:"name"`
+	Intro string `json:"intro,omitempty"`
+}
+
+// An OptionDescription is a description of an option in a Description.
+type OptionDescription struct {
+	Short     string   `json:"short,omitempty"`      // short name, without the dash
+	Long      string   `json:"long,omitempty"`       // long name, without the dashes
+	Help      string   `json:"help,omitempty"`       // help message
+	ValueName string   `json:"value_name,omitempty"` // name of the value, for usage
+	Default   string   `json:"default,omitempty"`    // default value
+	Flag      bool     `json:"flag,omitempty"`       // the option takes no value
+	Optional  bool     `json:"optional,omitempty"`   // the value is optional
+	Mandatory bool     `json:"mandatory,omitempty"`  // the option is required
+	Group     string   `json:"group,omitempty"`      // mutual exclusion group
+	Section   string   `json:"section,omitempty"`    // display section
+	Where     string   `json:"where,omitempty"`      // where the option was declared
+	Type      string   `json:"type,omitempty"`       // Go type of the value, if known
+	Values    []string `json:"values,omitempty"`     // values of an Enum
+
+	Aliases      []string `json:"aliases,omitempty"`       // aliases of the long name
+	ShortAliases []string `json:"short_aliases,omitempty"` // aliases of the short name
+
+	Hidden      bool   `json:"hidden,omitempty"`      // the option is hidden
+	Deprecated  bool   `json:"deprecated,omitempty"`  // the option is deprecated
+	Message     string `json:"message,omitempty"`     // why it is deprecated
+	Replacement string `json:"replacement,omitempty"` // name of its replacement
+}
+
+// Describe calls Describe on the command line options.
+func Describe() *Description {
+	return CommandLine.Describe()
+}
+
+// Describe returns a description of s and its options, in the order they are
+// displayed by PrintOptions.
+func (s *Set) Describe() *Description {
+	d := &Description{
+		Program:        s.program,
+		Parameters:     s.parameters,
+		RequiredGroups: append([]string(nil), s.requiredGroups...),
+		Options:        []*OptionDescription{},
+	}
+	for _, sec := range s.sections {
+		d.Sections = append(d.Sections, &SectionDescription{Name: sec.name, Intro: sec.intro})
+	}
+	sort.Sort(s.options)
+	for _, opt := range s.options {
+		od := &OptionDescription{
+			Long:      opt.long,
+			Help:      opt.help,
+			ValueName: opt.name,
+			Default:   opt.defval,
+			Flag:      opt.flag,
+			Optional:  opt.optional,
+			Mandatory: opt.mandatory,
+			Group:     opt.group,
+			Section:   opt.section,
+			Where:     opt.where,
+			Type:      valueType(opt.value),
+		}
+		if opt.short != 0 {
+			od.Short = string(opt.short)
+		}
+		if od.ValueName == "" && !opt.flag {
+			od.ValueName = "value"
+		}
+		if _, ok := opt.value.(*enumValue); ok {
+			od.Values, _ = completionValues(opt)
+		}
+		od.Aliases = append([]string(nil), opt.longAliases...)
+		for _, c := range opt.shortAliases {
+			od.ShortAliases = append(od.ShortAliases, string(c))
+		}
+		od.Hidden = opt.hidden
+		if dep := opt.deprecation; dep != nil {
+			od.Deprecated = true
+			od.Message = dep.msg
+			if r := dep.replacement; r != nil {
+				od.Replacement = r.long
+				if r.long == "" {
+					od.Replacement = string(r.short)
+				}
+			}
+		}
+		d.Options = append(d.Options, od)
+	}
+	return d
+}
+
+// valueType returns the name of the Go type of the value v.
+func valueType(v Value) string {
+	switch v.(type) {
+	case *enumValue:
+		return "string"
+	case *signed:
+		return "int64"
+	case *unsigned:
+		return "uint64"
+	case *counterValue:
+		return "int"
+	}
+	var t string
+	if p := genericValue(v); p != nil {
+		t = fmt.Sprintf("%T", p)
+	} else {
+		t = fmt.Sprintf("%T", v)
+	}
+	return strings.TrimPrefix(t, "*")
+}
+
+// WriteJSON calls WriteJSON on the command line options.
+func WriteJSON(w io.Writer) error {
+	return CommandLine.WriteJSON(w)
+}
+
+// WriteJSON writes the description of s (see Describe) to w as indented JSON.
+func (s *Set) WriteJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(s.Describe(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ReadJSON returns a new Set built from the JSON description of a Set read
+// from r, as written by WriteJSON.
+func ReadJSON(r io.Reader) (*Set, error) {
+	var d Description
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, err
+	}
+	return d.Set()
+}
+
+// Set returns a new Set with the options described by d.  The options are
+// backed by strings, or bools if they are flags, except that the options with
+// Values are Enums.  The values of the options can be retrieved from the
+// Value of each option, such as with Lookup.
+func (d *Description) Set() (*Set, error) {
+	s := New()
+	s.program = d.Program
+	if d.Parameters != "" {
+		s.parameters = d.Parameters
+	}
+	s.requiredGroups = append(s.requiredGroups, d.RequiredGroups...)
+	for _, sec := range d.Sections {
+		if sec != nil {
+			s.AddSection(sec.Name, sec.Intro)
+		}
+	}
+	for _, od := range d.Options {
+		if od == nil {
+			continue
+		}
+		opt, err := od.option()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range opt.shortNames() {
+			if s.shortOptions[c] != nil {
+				return nil, fmt.Errorf("%s: -%c already declared", opt.where, c)
+			}
+		}
+		for _, l := range opt.longNames() {
+			if s.longOptions[l] != nil {
+				return nil, fmt.Errorf("%s: --%s already declared", opt.where, l)
+			}
+		}
+		s.AddOption(opt)
+	}
+
+	// Replacements may be declared after the deprecated options.
+	for _, od := range d.Options {
+		if od == nil || od.Replacement == "" {
+			continue
+		}
+		opt := s.lookupName(od.Long, od.Short)
+		r := s.lookupName(od.Replacement, od.Replacement)
+		if r == nil {
+			return nil, fmt.Errorf("%s: unknown replacement %s", opt.Name(), od.Replacement)
+		}
+		opt.deprecation.replacement = r
+	}
+	return s, nil
+}
+
+// option returns a new option as described by od.
+func (od *OptionDescription) option() (*option, error) {
+	opt := &option{
+		long:      od.Long,
+		help:      od.Help,
+		name:      od.ValueName,
+		flag:      od.Flag,
+		optional:  od.Optional,
+		mandatory: od.Mandatory,
+		group:     od.Group,
+		section:   od.Section,
+		where:     od.Where,
+		hidden:    od.Hidden,
+	}
+	if od.Deprecated || od.Replacement != "" {
+		opt.deprecation = &deprecation{msg: od.Message}
+	}
+	if od.Short != "" {
+		r, err := shortName(od.Short)
+		if err != nil {
+			return nil, err
+		}
+		opt.short = r
+	}
+	for _, a := range od.ShortAliases {
+		r, err := shortName(a)
+		if err != nil {
+			return nil, err
+		}
+		opt.shortAliases = append(opt.shortAliases, r)
+	}
+	opt.longAliases = append(opt.longAliases, od.Aliases...)
+	name := od.Long
+	if name == "" {
+		name = od.Short
+	}
+	if name == "" {
+		return nil, fmt.Errorf("option has no name")
+	}
+	switch {
+	case len(od.Values) > 0:
+		var e enumValue
+		e.defineValues(od.Values)
+		opt.value = &e
+	case od.Flag:
+		var b bool
+		opt.value, _ = toValue(&b)
+	default:
+		var s string
+		opt.value, _ = toValue(&s)
+	}
+	if od.Default != "" {
+		if err := opt.value.Set(od.Default, opt); err != nil {
+			return nil, fmt.Errorf("setting default for %s: %v", name, err)
+		}
+	}
+	opt.defval = opt.value.String()
+	return opt, nil
+}
+
+// lookupName returns the option in s with the long name long, or if there is
+// none, with the short name short.
+func (s *Set) lookupName(long, short string) *option {
+	if opt := s.longOptions[long]; opt != nil {
+		return opt
+	}
+	if r, n := utf8.DecodeRuneInString(short); n > 0 && n == len(short) {
+		return s.shortOptions[r]
+	}
+	return nil
+}
+
+// shortName returns the short option name in name.
+func shortName(name string) (rune, error) {
+	r, n := utf8.DecodeRuneInString(name)
+	if n != len(name) || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid short option name %q", name)
+	}
+	return r, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	reset()
+	SetProgram("prog")
+	SetParameters("file ...")
+	var (
+		alpha, beta bool
+		count       int
+		timeout     time.Duration
+		output      = "-"
+	)
+	BoolLong("verbose", 'v', "be verbose")
+	FlagLong(&output, "output", 'o', "output file", "file").SetOptional()
+	FlagLong(&count, "count", 'c', "number of copies").Mandatory()
+	FlagLong(&timeout, "timeout", 0, "how long to wait")
+	EnumLong("mode", 0, []string{"slow", "fast"}, "slow", "speed")
+	FlagLong(&alpha, "alpha", 'a', "use alpha").SetGroup("method")
+	FlagLong(&beta, "beta", 'b', "use beta").SetGroup("method")
+	RequiredGroup("method")
+
+	d := CommandLine.Describe()
+	for _, od := range d.Options {
+		if path.Base(strings.Split(od.Where, ":")[0]) != "describe_test.go" {
+			t.Errorf("%s declared at %s", od.Long, od.Where)
+		}
+		od.Where = ""
+	}
+	want := &Description{
+		Program:        "prog",
+		Parameters:     "file ...",
+		RequiredGroups: []string{"method"},
+		Options: []*OptionDescription{
+			{Short: "a", Long: "alpha", Help: "use alpha", Default: "false", Flag: true, Group: "method", Type: "bool"},
+			{Short: "b", Long: "beta", Help: "use beta", Default: "false", Flag: true, Group: "method", Type: "bool"},
+			{Short: "c", Long: "count", Help: "number of copies", ValueName: "value", Default: "0", Mandatory: true, Type: "int"},
+			{Long: "mode", Help: "speed", ValueName: "value", Default: "slow", Type: "string", Values: []string{"fast", "slow"}},
+			{Short: "o", Long: "output", Help: "output file", ValueName: "file", Default: "-", Optional: true, Type: "string"},
+			{Long: "timeout", Help: "how long to wait", ValueName: "value", Default: "0s", Type: "time.Duration"},
+			{Short: "v", Long: "verbose", Help: "be verbose", Default: "false", Flag: true, Type: "bool"},
+		},
+	}
+	if !reflect.DeepEqual(d, want) {
+		got, _ := json.MarshalIndent(d, "", "  ")
+		t.Errorf("got:\n%s", got)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	reset()
+	SetProgram("prog")
+	SetParameters("file ...")
+	var (
+		alpha, beta bool
+		count       int
+		timeout     time.Duration
+		output      = "-"
+	)
+	BoolLong("verbose", 'v', "be verbose")
+	FlagLong(&output, "output", 'o', "output file", "file").SetOptional()
+	FlagLong(&count, "count", 'c', "number of copies").Mandatory()
+	FlagLong(&timeout, "timeout", 0, "how long to wait")
+	EnumLong("mode", 0, []string{"slow", "fast"}, "slow", "speed")
+	FlagLong(&alpha, "alpha", 'a', "use alpha").SetGroup("method")
+	FlagLong(&beta, "beta", 'b', "use beta").SetGroup("method")
+	RequiredGroup("method")
+
+	var b bytes.Buffer
+	if err := CommandLine.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The options are strings, so zero default values are displayed.
+	HelpColumn = 20
+	var help bytes.Buffer
+	s.PrintUsage(&help)
+	want := `Usage: prog [-abv] [-c value] [--mode value] [-o file] [--timeout value] file ...
+ -a, --alpha        use alpha {method}
+ -b, --beta         use beta {method}
+ -c, --count=value  number of copies [0] (required)
+     --mode=value   speed [slow]
+ -o, --output[=file]
+                    output file [-]
+     --timeout=value
+                    how long to wait [0s]
+ -v, --verbose      be verbose
+`
+	if help.String() != want {
+		t.Errorf("got usage:\n%s\nwant:\n%s", help.String(), want)
+	}
+	d := s.Describe()
+	if d.Options[2].Type != "string" || d.Options[0].Type != "bool" {
+		t.Errorf("got types %s and %s, want string and bool", d.Options[2].Type, d.Options[0].Type)
+	}
+	if d.Options[0].Where == "" {
+		t.Errorf("where not imported")
+	}
+
+	if err := s.Getopt([]string{"prog", "-a", "--mode=fast", "-c", "3", "--output=x", "file"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name  string
+		value string
+	}{
+		{"alpha", "true"},
+		{"beta", "false"},
+		{"count", "3"},
+		{"mode", "fast"},
+		{"output", "x"},
+	} {
+		if v := s.Lookup(tt.name).String(); v != tt.value {
+			t.Errorf("%s: got %q, want %q", tt.name, v, tt.value)
+		}
+	}
+	s.Reset()
+	if err := s.Getopt([]string{"prog", "--mode=medium", "-a", "-c", "1"}, nil); err == nil {
+		t.Errorf("did not get an error for an invalid enum value")
+	}
+	s.Reset()
+	if err := s.Getopt([]string{"prog", "-a"}, nil); err == nil {
+		t.Errorf("did not get an error for a missing mandatory option")
+	}
+
+	for _, bad := range []string{
+		`{"options": [{"short": "ab"}]}`,
+		`{"options": [{"help": "no name"}]}`,
+		`{"options": [{"long": "x"}, {"long": "x"}]}`,
+		`{"options": [{"short": "x"}, {"short": "x", "long": "y"}]}`,
+		`{"options": [{"long": "x", "values": ["a"], "default": "b"}]}`,
+		`{"options": [`,
+	} {
+		if _, err := ReadJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("%s: did not get an error", bad)
+		}
+	}
+}
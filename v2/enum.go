@@ -63,6 +63,17 @@ func (s *Set) EnumLong(name string, short rune, values []string, value string, h
 }
 
 func (e *enumValue) define(values []string, def string, opt Option) {
+	e.defineValues(values)
+	if def != "" {
+		if err := e.Set(def, nil); err != nil {
+			fmt.Fprintf(stderr, "setting default for %s: %v\n", opt.Name(), err)
+			exit(1)
+		}
+	}
+}
+
+// defineValues sets the values e may be set to.
+func (e *enumValue) defineValues(values []string) {
 	m := make(map[string]struct{})
 	for _, v := range values {
 		m[v] = struct{}{}
@@ -70,10 +81,4 @@ func (e *enumValue) define(values []string, def string, opt Option) {
 	enumValuesMu.Lock()
 	enumValues[e] = m
 	enumValuesMu.Unlock()
-	if def != "" {
-		if err := e.Set(def, nil); err != nil {
-			fmt.Fprintf(stderr, "setting default for %s: %v\n", opt.Name(), err)
-			exit(1)
-		}
-	}
 }
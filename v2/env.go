@@ -0,0 +1,105 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// lookupEnv allows tests to provide environment variables.
+var lookupEnv = os.LookupEnv
+
+// SetEnvPrefix calls SetEnvPrefix on the command line options.
+func SetEnvPrefix(prefix string) {
+	CommandLine.SetEnvPrefix(prefix)
+}
+
+// SetEnvPrefix binds each option in s that has a long name, and is not bound
+// with Option.Env, to the environment variable named by prefix, an
+// underscore, and the long name in upper case with dashes replaced by
+// underscores.  For example, with the prefix MYAPP the option --dry-run is
+// bound to MYAPP_DRY_RUN.  An empty prefix turns off automatic binding.
+func (s *Set) SetEnvPrefix(prefix string) {
+	s.envPrefix = prefix
+}
+
+func (o *option) Env(names ...string) Option {
+	o.env = append([]string{}, names...)
+	return o
+}
+
+// envNames returns the names of the environment variables opt is bound to
+// in s.
+func (s *Set) envNames(opt *option) []string {
+	if opt.env != nil || s.envPrefix == "" || opt.long == "" {
+		return opt.env
+	}
+	name := strings.ToUpper(strings.Replace(opt.long, "-", "_", -1))
+	return []string{s.envPrefix + "_" + name}
+}
+
+// envUsage returns the environment variables opt is bound to in s as
+// displayed in the usage, e.g., "[$MYAPP_TIMEOUT]", or "".
+func (s *Set) envUsage(opt *option) string {
+	names := s.envNames(opt)
+	if len(names) == 0 {
+		return ""
+	}
+	return "[$" + strings.Join(names, ", $") + "]"
+}
+
+// setEnv sets each option in s that was not seen while parsing from the
+// first non-empty environment variable it is bound to.  An option in a
+// mutually exclusive group is not set if another option in the group was
+// seen, and is reset if it was set from a configuration (see LoadConfig).
+func (s *Set) setEnv() error {
+	groups := map[string]bool{}
+	for _, opt := range s.options {
+		opt.envSet = ""
+		if opt.Seen() && opt.group != "" {
+			groups[opt.group] = true
+		}
+	}
+	for _, opt := range s.options {
+		if opt.Seen() {
+			continue
+		}
+		if opt.group != "" && groups[opt.group] {
+			if opt.config != "" {
+				opt.value.Set(opt.defval, opt)
+				opt.config = ""
+			}
+			continue
+		}
+		for _, name := range s.envNames(opt) {
+			value, ok := lookupEnv(name)
+			if !ok || value == "" {
+				continue
+			}
+			if err := opt.setValue(value); err != nil {
+				return envError(opt, name, value, err)
+			}
+			opt.envSet = name
+			break
+		}
+	}
+	return nil
+}
+
+// present returns true if o was seen while parsing or was set from the
+// environment or a configuration.
+func (o *option) present() bool {
+	return o.Seen() || o.envSet != "" || o.config != ""
+}
+
+// envError returns an Error indicating option o could not be set to value
+// from the environment variable name.
+func envError(o Option, name, value string, err error) *Error {
+	e := setError(o, value, err)
+	e.Err = fmt.Errorf("$%s: %v", name, err)
+	return e
+}
@@ -0,0 +1,160 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+var envTests = []struct {
+	where   string
+	env     map[string]string
+	in      []string
+	timeout time.Duration
+	name    string
+	a, b    bool
+	err     string
+}{
+	{
+		where:   loc(),
+		in:      []string{"test", "-a"},
+		timeout: time.Second,
+		a:       true,
+	},
+	{
+		where:   loc(),
+		env:     map[string]string{"MYAPP_TIMEOUT": "5s", "MYAPP_DRY_RUN": "x", "NAME": "fred"},
+		in:      []string{"test", "-a"},
+		timeout: 5 * time.Second,
+		name:    "fred",
+		a:       true,
+	},
+	{
+		where:   loc(),
+		env:     map[string]string{"MYAPP_TIMEOUT": "5s"},
+		in:      []string{"test", "-a", "--timeout=3s"},
+		timeout: 3 * time.Second,
+		a:       true,
+	},
+	{
+		where:   loc(),
+		env:     map[string]string{"ALT_NAME": "barney", "NAME": ""},
+		in:      []string{"test", "-a"},
+		timeout: time.Second,
+		name:    "barney",
+		a:       true,
+	},
+	{
+		where:   loc(),
+		env:     map[string]string{"B": "true"},
+		in:      []string{"test"},
+		timeout: time.Second,
+		b:       true,
+	},
+	{
+		where:   loc(),
+		env:     map[string]string{"B": "true"},
+		in:      []string{"test", "-a"},
+		timeout: time.Second,
+		a:       true,
+	},
+	{
+		where:   loc(),
+		env:     map[string]string{"MYAPP_TIMEOUT": "soon"},
+		in:      []string{"test", "-a"},
+		timeout: time.Second,
+		a:       true,
+		err:     "test: $MYAPP_TIMEOUT: time: invalid duration",
+	},
+}
+
+func TestEnv(t *testing.T) {
+	defer func(le func(string) (string, bool)) { lookupEnv = le }(lookupEnv)
+	for _, tt := range envTests {
+		lookupEnv = func(name string) (string, bool) {
+			v, ok := tt.env[name]
+			return v, ok
+		}
+		reset()
+		timeout := time.Second
+		var name, dryRun string
+		var a, b bool
+		SetEnvPrefix("MYAPP")
+		FlagLong(&timeout, "timeout", 't')
+		FlagLong(&name, "name", 0).Env("NAME", "ALT_NAME")
+		FlagLong(&dryRun, "dry-run", 0).Env()
+		Flag(&a, 'a').SetGroup("ab")
+		Flag(&b, 'b').SetGroup("ab").Env("B")
+		RequiredGroup("ab")
+
+		parse(tt.in)
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.where, s)
+		}
+		if timeout != tt.timeout || name != tt.name || a != tt.a || b != tt.b {
+			t.Errorf("%s: got %v %q %v %v, want %v %q %v %v", tt.where, timeout, name, a, b, tt.timeout, tt.name, tt.a, tt.b)
+		}
+		if dryRun != "" {
+			t.Errorf("%s: dry-run set to %q", tt.where, dryRun)
+		}
+	}
+}
+
+func TestEnvMandatory(t *testing.T) {
+	defer func(le func(string) (string, bool)) { lookupEnv = le }(lookupEnv)
+	lookupEnv = func(name string) (string, bool) { return "x", name == "HOST" }
+	reset()
+	var host string
+	FlagLong(&host, "host", 0).Mandatory().Env("HOST")
+	parse([]string{"test"})
+	if errorString != "" {
+		t.Fatalf("unexpected error %s", errorString)
+	}
+	if host != "x" || IsSet("host") {
+		t.Errorf("got host %q (seen %v), want \"x\" (seen false)", host, IsSet("host"))
+	}
+}
+
+func TestEnvTerminated(t *testing.T) {
+	defer func(le func(string) (string, bool)) { lookupEnv = le }(lookupEnv)
+	lookupEnv = func(name string) (string, bool) { return "5", name == "X_TO" }
+	reset()
+	var v bool
+	var timeout int
+	Flag(&v, 'v')
+	Flag(&timeout, 't').Mandatory().Env("X_TO")
+	err := CommandLine.Getopt([]string{"test", "-v", "file"}, func(Option) bool { return false })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if timeout != 5 {
+		t.Errorf("got timeout %d, want 5", timeout)
+	}
+	if got := CommandLine.State(); got != Terminated {
+		t.Errorf("got state %v, want %v", got, Terminated)
+	}
+}
+
+func TestEnvUsage(t *testing.T) {
+	reset()
+	HelpColumn = 20
+	var timeout time.Duration
+	var name string
+	SetEnvPrefix("MYAPP")
+	FlagLong(&timeout, "timeout", 't', "the timeout")
+	FlagLong(&name, "name", 0, "the name").Env("NAME", "ALT_NAME")
+	want := `
+     --name=value  the name [$NAME, $ALT_NAME]
+ -t, --timeout=value
+                   the timeout [$MYAPP_TIMEOUT]
+`[1:]
+	var buf bytes.Buffer
+	CommandLine.PrintOptions(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
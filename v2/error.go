@@ -4,7 +4,10 @@
 
 package getopt
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // An Error is returned by Getopt when it encounters an error.
 type Error struct {
@@ -12,6 +15,31 @@ type Error struct {
 	Err       error  // The actual error.
 	Parameter string // Parameter passed to option, if any
 	Name      string // Option that cause error, if any
+
+	// Candidates are the names of the options an ambiguous
+	// option could refer to.
+	Candidates []string
+
+	// Suggestions are the names of the options, closest first, that an
+	// unknown option may have been meant to be (see SetSuggestDistance).
+	Suggestions []string
+
+	// Options are the options involved in an error found after parsing,
+	// such as MissingMandatory or ConflictingOptions, and Group is the
+	// name of the group of a MutuallyExclusive or MissingRequiredGroup
+	// error.
+	Options []Option
+	Group   string
+
+	// Index is the index, in the arguments passed to Getopt, of the
+	// argument that caused the error.  It is 0 if the error was not
+	// caused by a specific argument.
+	Index int
+
+	// File and Line are the name of the response file and the line
+	// within it that caused the error, if any.
+	File string
+	Line int
 }
 
 // Error returns the error message, implementing the error interface.
@@ -26,24 +54,93 @@ const (
 	MissingParameter // the options parameter is missing
 	ExtraParameter   // a value was set to a long flag
 	Invalid          // attempt to set an invalid value
+	AmbiguousOption  // an abbreviated option matched more than one option
+	BadResponseFile  // a response file could not be read or parsed
+
+	MissingPositional  // a required positional parameter is missing
+	TooManyPositionals // more arguments than positional parameters
+
+	UnknownCommand // an unknown subcommand was named
+	MissingCommand // no subcommand was named
+
+	MissingMandatory     // a mandatory option was not set
+	MutuallyExclusive    // more than one option of a group was set
+	MissingRequiredGroup // no option of a required group was set
+
+	MissingRequirement // an option required by another was not set
+	ConflictingOptions // options that conflict with each other were set
+	IncompleteOptions  // some but not all of a set of options were set
+	TooFewOptions      // fewer of a set of options were set than required
+	TooManyOptions     // more of a set of options were set than allowed
+
+	ConditionallyRequired  // an option required by the value of another was not set
+	ConditionallyForbidden // an option forbidden by the value of another was set
+	ValidationFailed       // a validator of a Set returned an error
 )
 
+// LegacyErrorCodeStrings causes ErrorCode.String to return the misspelled
+// descriptions of UnknownOption and ExtraParameter returned by earlier
+// versions, "unknow option" and "unxpected value", for programs that depend
+// on them.
+var LegacyErrorCodeStrings = false
+
 func (e ErrorCode) String() string {
 	switch e {
 	case UnknownOption:
-		return "unknow option"
+		if LegacyErrorCodeStrings {
+			return "unknow option"
+		}
+		return "unknown option"
 	case MissingParameter:
 		return "missing argument"
 	case ExtraParameter:
-		return "unxpected value"
+		if LegacyErrorCodeStrings {
+			return "unxpected value"
+		}
+		return "unexpected value"
 	case Invalid:
 		return "error setting value"
+	case AmbiguousOption:
+		return "ambiguous option"
+	case BadResponseFile:
+		return "bad response file"
+	case MissingPositional:
+		return "missing positional parameter"
+	case TooManyPositionals:
+		return "too many arguments"
+	case UnknownCommand:
+		return "unknown command"
+	case MissingCommand:
+		return "missing command"
+	case MissingMandatory:
+		return "missing mandatory option"
+	case MutuallyExclusive:
+		return "mutually exclusive options"
+	case MissingRequiredGroup:
+		return "missing required group"
+	case MissingRequirement:
+		return "missing required option"
+	case ConflictingOptions:
+		return "conflicting options"
+	case IncompleteOptions:
+		return "incomplete options"
+	case TooFewOptions:
+		return "too few options"
+	case TooManyOptions:
+		return "too many options"
+	case ConditionallyRequired:
+		return "missing conditionally required option"
+	case ConditionallyForbidden:
+		return "conditionally forbidden option"
+	case ValidationFailed:
+		return "validation failed"
 	}
 	return "unknown error"
 }
 
 // unknownOption returns an Error indicating an unknown option was
-// encountered.
+// encountered.  Name is either the rune of a short option or the full name of
+// a long option, including its dashes.
 func unknownOption(name interface{}) *Error {
 	i := &Error{ErrorCode: UnknownOption}
 	switch n := name.(type) {
@@ -54,12 +151,77 @@ func unknownOption(name interface{}) *Error {
 			i.Name = "-" + string(n)
 		}
 	case string:
-		i.Name = "--" + n
+		i.Name = n
 	}
 	i.Err = fmt.Errorf("unknown option: %s", i.Name)
 	return i
 }
 
+// ambiguousOption returns an Error indicating the abbreviated long option
+// name matched each of the options in candidates.
+func ambiguousOption(name string, candidates []string) *Error {
+	return &Error{
+		ErrorCode:  AmbiguousOption,
+		Name:       name,
+		Candidates: candidates,
+		Err:        fmt.Errorf("ambiguous option: %s could be %s", name, strings.Join(candidates, ", ")),
+	}
+}
+
+// responseError returns an Error indicating err was encountered while
+// expanding the response file reference name found in file at line.  If file
+// is "" then name was an argument passed to Getopt.
+func responseError(name, file string, line int, err error) *Error {
+	if file != "" {
+		err = fmt.Errorf("%s:%d: %v", file, line, err)
+	}
+	return &Error{
+		ErrorCode: BadResponseFile,
+		Name:      name,
+		File:      file,
+		Line:      line,
+		Err:       err,
+	}
+}
+
+// missingPositional returns an Error indicating the required positional
+// parameter o was not provided.
+func missingPositional(o Option) *Error {
+	return &Error{
+		ErrorCode: MissingPositional,
+		Name:      o.Name(),
+		Err:       fmt.Errorf("missing %s", o.Name()),
+	}
+}
+
+// tooManyPositionals returns an Error indicating arg was not assigned to a
+// positional parameter.
+func tooManyPositionals(arg string) *Error {
+	return &Error{
+		ErrorCode: TooManyPositionals,
+		Parameter: arg,
+		Err:       fmt.Errorf("unexpected argument: %s", arg),
+	}
+}
+
+// unknownCommand returns an Error indicating the unknown subcommand name
+// was encountered.
+func unknownCommand(name string) *Error {
+	return &Error{
+		ErrorCode: UnknownCommand,
+		Name:      name,
+		Err:       fmt.Errorf("unknown command: %s", name),
+	}
+}
+
+// missingCommand returns an Error indicating no subcommand was named.
+func missingCommand() *Error {
+	return &Error{
+		ErrorCode: MissingCommand,
+		Err:       fmt.Errorf("missing command"),
+	}
+}
+
 // missingArg returns an Error inidicating option o was not passed
 // a required paramter.
 func missingArg(o Option) *Error {
@@ -91,3 +253,127 @@ func setError(o Option, value string, err error) *Error {
 		Err:       err,
 	}
 }
+
+// missingMandatory returns an Error indicating the mandatory option o was not
+// set.
+func missingMandatory(o Option) *Error {
+	return &Error{
+		ErrorCode: MissingMandatory,
+		Name:      o.Name(),
+		Options:   []Option{o},
+		Err:       fmt.Errorf("option %s is mandatory", o.Name()),
+	}
+}
+
+// mutuallyExclusive returns an Error indicating the options o1 and o2 of
+// group were both set.
+func mutuallyExclusive(group string, o1, o2 Option) *Error {
+	return &Error{
+		ErrorCode: MutuallyExclusive,
+		Name:      o2.Name(),
+		Options:   []Option{o1, o2},
+		Group:     group,
+		Err:       fmt.Errorf("options %s and %s are mutually exclusive", o1.Name(), o2.Name()),
+	}
+}
+
+// missingRequiredGroup returns an Error indicating none of the options of the
+// required group were set.
+func missingRequiredGroup(group string, opts []Option) *Error {
+	return &Error{
+		ErrorCode: MissingRequiredGroup,
+		Options:   opts,
+		Group:     group,
+		Err:       fmt.Errorf("exactly one of the following options must be specified: %s", optionNames(opts)),
+	}
+}
+
+// optionNames returns the names of opts separated by commas.
+func optionNames(opts []Option) string {
+	var names []string
+	for _, o := range opts {
+		names = append(names, o.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+// missingRequirement returns an Error indicating o was set but the option it
+// requires, required, was not.
+func missingRequirement(o, required Option) *Error {
+	return &Error{
+		ErrorCode: MissingRequirement,
+		Name:      o.Name(),
+		Options:   []Option{o, required},
+		Err:       fmt.Errorf("option %s requires %s", o.Name(), required.Name()),
+	}
+}
+
+// conflictingOptions returns an Error indicating o and the option it
+// conflicts with, other, were both set.
+func conflictingOptions(o, other Option) *Error {
+	return &Error{
+		ErrorCode: ConflictingOptions,
+		Name:      o.Name(),
+		Options:   []Option{o, other},
+		Err:       fmt.Errorf("option %s conflicts with %s", o.Name(), other.Name()),
+	}
+}
+
+// incompleteOptions returns an Error indicating some, but not all, of opts
+// were set.
+func incompleteOptions(opts []Option) *Error {
+	return &Error{
+		ErrorCode: IncompleteOptions,
+		Options:   opts,
+		Err:       fmt.Errorf("either all or none of the following options must be specified: %s", optionNames(opts)),
+	}
+}
+
+// tooFewOptions returns an Error indicating none of opts were set.
+func tooFewOptions(opts []Option) *Error {
+	return &Error{
+		ErrorCode: TooFewOptions,
+		Options:   opts,
+		Err:       fmt.Errorf("at least one of the following options must be specified: %s", optionNames(opts)),
+	}
+}
+
+// tooManyOptions returns an Error indicating more than n of opts were set.
+func tooManyOptions(n int, opts []Option) *Error {
+	return &Error{
+		ErrorCode: TooManyOptions,
+		Options:   opts,
+		Err:       fmt.Errorf("at most %d of the following options may be specified: %s", n, optionNames(opts)),
+	}
+}
+
+// conditionallyRequired returns an Error indicating o was not set but is
+// required by the value of other.
+func conditionallyRequired(o, other Option) *Error {
+	return &Error{
+		ErrorCode: ConditionallyRequired,
+		Name:      o.Name(),
+		Options:   []Option{o, other},
+		Err:       fmt.Errorf("option %s is required by %s=%s", o.Name(), other.Name(), other.String()),
+	}
+}
+
+// conditionallyForbidden returns an Error indicating o was set but is
+// forbidden by the value of other.
+func conditionallyForbidden(o, other Option) *Error {
+	return &Error{
+		ErrorCode: ConditionallyForbidden,
+		Name:      o.Name(),
+		Options:   []Option{o, other},
+		Err:       fmt.Errorf("option %s is not allowed with %s=%s", o.Name(), other.Name(), other.String()),
+	}
+}
+
+// validationFailed returns err, returned by a validator of a Set, as an
+// Error.
+func validationFailed(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{ErrorCode: ValidationFailed, Err: err}
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import "testing"
+
+func TestErrorCodeString(t *testing.T) {
+	defer func(legacy bool) { LegacyErrorCodeStrings = legacy }(LegacyErrorCodeStrings)
+	for _, tt := range []struct {
+		where  string
+		code   ErrorCode
+		legacy bool
+		want   string
+	}{
+		{loc(), UnknownOption, false, "unknown option"},
+		{loc(), UnknownOption, true, "unknow option"},
+		{loc(), ExtraParameter, false, "unexpected value"},
+		{loc(), ExtraParameter, true, "unxpected value"},
+		{loc(), MissingParameter, true, "missing argument"},
+		{loc(), MissingMandatory, false, "missing mandatory option"},
+		{loc(), MutuallyExclusive, false, "mutually exclusive options"},
+		{loc(), MissingRequiredGroup, false, "missing required group"},
+		{loc(), ErrorCode(-1), false, "unknown error"},
+	} {
+		LegacyErrorCodeStrings = tt.legacy
+		if got := tt.code.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestCheckOptionsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		where   string
+		in      []string
+		code    ErrorCode
+		name    string
+		group   string
+		options []string
+		err     string
+	}{
+		{
+			where:   loc(),
+			in:      []string{"test", "-A"},
+			code:    MissingMandatory,
+			name:    "-r",
+			options: []string{"-r"},
+			err:     "option -r is mandatory",
+		},
+		{
+			where:   loc(),
+			in:      []string{"test", "-r", "-A", "--bee"},
+			code:    MutuallyExclusive,
+			name:    "--bee",
+			group:   "One",
+			options: []string{"-A", "--bee"},
+			err:     "options -A and --bee are mutually exclusive",
+		},
+		{
+			where:   loc(),
+			in:      []string{"test", "-r"},
+			code:    MissingRequiredGroup,
+			group:   "One",
+			options: []string{"-A", "-B"},
+			err:     "exactly one of the following options must be specified: -A, -B",
+		},
+	} {
+		s := New()
+		var val bool
+		s.Flag(&val, 'r').Mandatory()
+		s.Flag(&val, 'A').SetGroup("One")
+		s.FlagLong(&val, "bee", 'B').SetGroup("One")
+		s.RequiredGroup("One")
+		err := s.Getopt(tt.in, nil)
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: got error %v (%T), want an *Error", tt.where, err, err)
+			continue
+		}
+		if e.ErrorCode != tt.code {
+			t.Errorf("%s: got code %v, want %v", tt.where, e.ErrorCode, tt.code)
+		}
+		if e.Name != tt.name {
+			t.Errorf("%s: got name %q, want %q", tt.where, e.Name, tt.name)
+		}
+		if e.Group != tt.group {
+			t.Errorf("%s: got group %q, want %q", tt.where, e.Group, tt.group)
+		}
+		var options []string
+		for _, o := range e.Options {
+			options = append(options, o.Name())
+		}
+		if badSlice(options, tt.options) {
+			t.Errorf("%s: got options %q, want %q", tt.where, options, tt.options)
+		}
+		if got := e.Error(); got != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.where, got, tt.err)
+		}
+	}
+}
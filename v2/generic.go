@@ -59,21 +59,32 @@ func (s *Set) long(v interface{}, long string, short rune, helpvalue ...string)
 			}
 		}()
 	}
+	p, flag := toValue(v)
+	opt = s.addFlag(p, long, short, helpvalue...)
+	if flag {
+		opt.SetFlag()
+	}
+	return opt
+}
+
+// toValue returns v as a Value.  Flag is true if v is a pointer to a bool.
+// toValue panics if v is not a Value or one of the supported builtin types.
+func toValue(v interface{}) (value Value, flag bool) {
 	switch p := v.(type) {
 	case Value:
-		return s.addFlag(p, long, short, helpvalue...)
+		return p, false
 	case *bool:
-		return s.addFlag(&generic{v}, long, short, helpvalue...).SetFlag()
+		return &generic{v}, true
 	case *string, *[]string:
-		return s.addFlag(&generic{v}, long, short, helpvalue...)
+		return &generic{v}, false
 	case *int, *int8, *int16, *int32, *int64:
-		return s.addFlag(&generic{v}, long, short, helpvalue...)
+		return &generic{v}, false
 	case *uint, *uint8, *uint16, *uint32, *uint64:
-		return s.addFlag(&generic{v}, long, short, helpvalue...)
+		return &generic{v}, false
 	case *float32, *float64:
-		return s.addFlag(&generic{v}, long, short, helpvalue...)
+		return &generic{v}, false
 	case *time.Duration:
-		return s.addFlag(&generic{v}, long, short, helpvalue...)
+		return &generic{v}, false
 	default:
 		panic(fmt.Sprintf("unsupported flag type: %T", v))
 	}
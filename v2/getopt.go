@@ -81,6 +81,33 @@
 //  --val=value (sets val to "value")
 //  --valvalue  (invalid option "valvalue")
 //
+// Flags and lists with long names may be made negatable with Negatable, or
+// for all options in a set with SetNegatable.  A negated flag is set to false,
+// a negated counter is set to 0 and a negated list is set to empty:
+//
+//  --no-color  (sets color to false)
+//
+// Response files are enabled with SetResponseFiles.  An argument of the form
+// @path is replaced by the words in the file path, as is done by gcc.
+//
+// Some programs, such as find and X11 programs, use a single dash for long
+// options.  SetSingleDash(SingleDashLong) causes an argument starting with a
+// single dash to first be matched against the long options.  If there is no
+// match then it is processed as short options.  SetSingleDash(SingleDashStrict)
+// turns off the processing of clustered short options.  In either mode the
+// usage displays long options with a single dash:
+//
+//  -name value  (sets name to "value")
+//  -name=value  (sets name to "value")
+//  -vx          (sets v and x if vx is not a long option)
+//
+// If SetAbbreviations(true) has been called then long options may be
+// abbreviated to any unique prefix, as with GNU getopt_long.  An exact match
+// is always preferred.  If the long options are verbose and version then:
+//
+//  --verb      (same as --verbose)
+//  --ver       (error: ambiguous option: --ver could be --verbose, --version)
+//
 // Values with an optional value only set the value if the value is part of the
 // same argument.  In any event, the option count is increased and the option is
 // marked as seen.
@@ -101,6 +128,16 @@
 //
 // Parsing continues until the first non-option or "--" is encountered.
 //
+// GNU getopt_long style permuting is enabled with SetPermute.  When permuting,
+// options and non-options may be mixed and parsing continues until "--" or
+// the end of the arguments.  The non-options are collected, in order, into
+// Args:
+//
+//  prog file1 -v file2 -- -x  (sets v, Args returns file1, file2, -x)
+//
+// Permuting is disabled if the POSIXLY_CORRECT environment variable is set or
+// SetPosixlyCorrect(true) has been called.
+//
 // The short name "-" can be used, but it either is specified as "-" or as part
 // of a group of options, for example "-f-".  If there are no long options
 // specified then "--f" could also be used.  If "-" is not declared as an option
@@ -121,7 +158,8 @@
 //	}
 //
 // When calling Getopt it is the responsibility of the caller to print any
-// errors.
+// errors.  Getopt stops at the first error unless SetCollectErrors is set, in
+// which case it returns all of them.
 //
 // Normally the default option set, CommandLine, is used.  Other option sets may
 // be created with New.
@@ -164,6 +202,25 @@
 //
 //	--path=value    the path (required)
 //
+// ENVIRONMENT VARIABLES
+//
+// An option may be bound to environment variables with Env, or all options
+// with long names in a set may be bound with SetEnvPrefix.  An option that is
+// not seen while parsing is set from its environment variable, so values on
+// the command line take precedence:
+//
+//	getopt.FlagLong(&timeout, "timeout", 't', "the timeout").Env("MYAPP_TIMEOUT")
+//
+// The variables are displayed in the help message:
+//
+//	-t, --timeout=value  the timeout [$MYAPP_TIMEOUT]
+//
+// CONFIGURATION FILES
+//
+// Options may also be set from INI or JSON configuration files, keyed by their
+// long names, with LoadConfig and LoadConfigFile.  Options seen on the command
+// line or set from the environment take precedence over the configuration.
+//
 // MUTUALLY EXCLUSIVE OPTIONS
 //
 // Options can be marked as part of a mutually exclusive group.  When two or
@@ -180,6 +237,138 @@
 //	 -a    use method A {method}
 //	 -b    use method B {method}
 //
+// Errors for missing mandatory options, mutually exclusive options and
+// required groups are returned as an *Error with the ErrorCode
+// MissingMandatory, MutuallyExclusive or MissingRequiredGroup, and the options
+// involved in its Options.
+//
+// CONSTRAINTS
+//
+// More general rules about which options may be used together are declared
+// with Requires and ConflictsWith on an option, and AllOrNone, AtLeastOne and
+// AtMost on a set:
+//
+//	cert := getopt.FlagLong(&certFile, "cert", 0, "certificate file")
+//	key := getopt.FlagLong(&keyFile, "key", 0, "key file")
+//	cert.Requires(key)
+//	getopt.AllOrNone(user, password)
+//
+// Each constraint is described in the help messages of its options:
+//
+//	--cert=value  certificate file (requires --key)
+//
+// Constraints may also depend on the values of other options with RequiredIf
+// and ForbiddenIf, and AddValidator adds a function that checks the parsed
+// options in any way:
+//
+//	cert.RequiredIf(mode, getopt.HasValue("tls"))
+//	port.ForbiddenIf(socket, getopt.Option.Seen)
+//
+// VALIDATION
+//
+// The values of an option may be checked as they are set with Validate.  The
+// Range, NonEmpty, Regexp and Finite validators are provided, and any
+// function can be used as a ValidatorFunc:
+//
+//	getopt.FlagLong(&port, "port", 'p', "the port").Validate(getopt.Range(1, 65535))
+//
+// A value that fails validation is reported as an Error with the ErrorCode
+// Invalid.  With SetShowRanges the ranges are displayed in the help messages:
+//
+//	-p, --port=value  the port (range 1..65535)
+//
+// HELP SECTIONS
+//
+// Programs with many options may display them in sections, each with its own
+// heading and optional introduction.  Options not in a section are displayed
+// first.  Sections only affect how options are displayed:
+//
+//	getopt.AddSection("Network options", "Options for reaching the server.")
+//	getopt.FlagLong(&host, "host", 'H', "server host").SetSection("Network options")
+//
+// With SetCollapseSections the usage line lists each section as
+// "[network options]" rather than its options.
+//
+// ALIASES
+//
+// An option may have additional long and short names, such as when an option
+// is renamed.  Name reports the name that was used:
+//
+//	getopt.FlagLong(&color, "color", 'c', "use color").Alias("colour")
+//
+// Only the primary names are displayed unless SetShowAliases is set.
+//
+// When an unknown option is close to the name of an option, such as
+// --verbsoe for --verbose, Parse suggests the option and the Suggestions of
+// the returned Error list it.  SetSuggestDistance sets how close it must be.
+//
+// HIDDEN AND DEPRECATED OPTIONS
+//
+// A hidden option is parsed as usual but is only displayed by PrintUsageAll.
+// A deprecated option warns when it is first used and may forward its values
+// to the option that replaces it:
+//
+//	getopt.FlagLong(&debug, "debug", 0, "debug mode").Hidden()
+//	name := getopt.FlagLong(&newName, "new-name", 0, "the name")
+//	getopt.FlagLong(&oldName, "old-name", 0).Deprecated("use --new-name", name)
+//
+// POSITIONAL PARAMETERS
+//
+// The non-option arguments may be declared as typed positional parameters
+// using Positional.  Getopt assigns them in order and reports an error if
+// there are too few or too many arguments, or if an argument cannot be
+// converted.  The usage line is generated from the declarations:
+//
+//	getopt.Positional(&src, "src")
+//	getopt.Positional(&count, "count").SetOptional()
+//	getopt.Positional(&files, "file").SetVariadic()
+//
+//	Usage: prog [-v] src [count] file ...
+//
+// COMMANDS
+//
+// Programs with subcommands, such as "git commit", may be built with Command.
+// Each Command has its own Set of options, an optional Run function and may
+// have subcommands.  Options declared in a command's Persistent set are
+// inherited by its subcommands:
+//
+//	root := &getopt.Command{Name: "prog"}
+//	root.Persistent().Flag(&verbose, 'v', "be verbose")
+//	add := &getopt.Command{Name: "add", Help: "add files", Run: runAdd}
+//	add.Set().Flag(&force, 'f', "force")
+//	root.AddCommand(add)
+//	root.Main(os.Args)
+//
+// SHELL COMPLETION
+//
+// WriteCompletion writes a bash, zsh or fish script that completes the options
+// of a program, including the values of Enum options.  AddCompletionOption
+// adds a hidden --completion=shell option that prints the script and exits:
+//
+//	getopt.AddCompletionOption()
+//	getopt.Parse()
+//
+//	$ source <(prog --completion=bash)
+//
+// With SetDynamicCompletion the script instead calls back into the program,
+// which completes the arguments using its own options.  Values may then be
+// completed at run time with a Completer, such as CompleteFiles, CompleteDirs
+// or one that lists host names:
+//
+//	getopt.FlagLong(&host, "host", 'H', "remote host").SetCompleter(hosts)
+//
+// JSON DESCRIPTIONS
+//
+// WriteJSON writes a description of a Set and its options as JSON, for use by
+// other tools.  ReadJSON builds a Set from such a description, so options may
+// also be declared outside of Go.
+//
+// MAN PAGES
+//
+// WriteManPage writes a man page in roff format with the same options and help
+// messages as PrintUsage.  The description, environment, exit status and
+// examples are supplied in a ManPage.
+//
 // BUILTIN TYPES
 //
 // The Flag and FlagLong functions support most standard Go types.  For the
@@ -229,7 +418,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path"
 	"sort"
 	"strings"
 	"time"
@@ -268,6 +456,59 @@ func (s *Set) PrintUsage(w io.Writer) {
 	s.PrintOptions(w)
 }
 
+// helpMessage returns the help message of opt followed by its default value,
+// environment variables, group, constraints, range and whether it is
+// required.
+func (s *Set) helpMessage(opt *option) string {
+	helpMsg := opt.help
+
+	// If the default value is the known zero value
+	// then don't display it.
+	def := opt.defval
+	switch genericValue(opt.value).(type) {
+	case *bool:
+		if def == "false" {
+			def = ""
+		}
+	case *int, *int8, *int16, *int32, *int64,
+		*uint, *uint8, *uint16, *uint32, *uint64,
+		*float32, *float64:
+		if def == "0" {
+			def = ""
+		}
+	case *time.Duration:
+		if def == "0s" {
+			def = ""
+		}
+	default:
+		if opt.flag && def == "false" {
+			def = ""
+		}
+	}
+	if def != "" {
+		helpMsg += " [" + def + "]"
+	}
+	if env := s.envUsage(opt); env != "" {
+		helpMsg += " " + env
+	}
+	if opt.group != "" {
+		helpMsg += " {" + opt.group + "}"
+	}
+	if c := s.constraintHelp(opt); c != "" {
+		helpMsg += " " + c
+	}
+	if r := rangeHelp(opt); r != "" && s.showRanges {
+		helpMsg += " " + r
+	}
+	if opt.mandatory {
+		helpMsg += " (required)"
+	}
+	if opt.deprecation != nil {
+		helpMsg += " (deprecated)"
+	}
+	return helpMsg
+}
+
 // UsageLine returns the usage line for the set s.  The set's program name and
 // parameters, if any, are not included.
 func (s *Set) UsageLine() string {
@@ -285,8 +526,9 @@ func (s *Set) UsageLine() string {
 		if opt.name == "" {
 			opt.name = "value"
 		}
-		if opt.uname == "" {
-			opt.uname = opt.usageName()
+		opt.uname = s.usageName(opt)
+		if s.hides(opt) || s.collapsed(opt) {
+			continue
 		}
 		if opt.flag && opt.short != 0 && opt.short != '-' {
 			flags += string(opt.short)
@@ -300,26 +542,42 @@ func (s *Set) UsageLine() string {
 		opts = append(opts, "-")
 	}
 
-	// If we have a bundle of flags, add them to the list
-	if flags != "" {
+	// If we have a bundle of flags, add them to the list.  When short
+	// options cannot be clustered, list them individually.
+	if s.singleDash == SingleDashStrict {
+		for _, c := range flags {
+			opts = append(opts, "-"+string(c))
+		}
+	} else if flags != "" {
 		opts = append(opts, "-"+flags)
 	}
+	dash := s.longDash()
 
 	// Now append all the long options and options that require
 	// values.
 	for _, opt := range s.options {
+		if s.hides(opt) || s.collapsed(opt) {
+			continue
+		}
 		if opt.flag {
 			if opt.short != 0 {
 				continue
 			}
-			flags = "--" + opt.long
+			flags = dash + s.negationFor(opt).usage(opt.long)
 		} else if opt.short != 0 {
 			flags = "-" + string(opt.short) + " " + opt.name
 		} else {
-			flags = "--" + string(opt.long) + " " + opt.name
+			flags = dash + string(opt.long) + " " + opt.name
 		}
 		opts = append(opts, flags)
 	}
+
+	// Collapsed sections are listed by name.
+	if s.collapseSections {
+		for _, sec := range s.displaySections() {
+			opts = append(opts, strings.ToLower(sec.name))
+		}
+	}
 	flags = strings.Join(opts, "] [")
 	if flags != "" {
 		flags = "[" + flags + "]"
@@ -335,57 +593,40 @@ func (s *Set) PrintOptions(w io.Writer) {
 		if opt.name == "" {
 			opt.name = "value"
 		}
-		if opt.uname == "" {
-			opt.uname = opt.usageName()
+		opt.uname = s.usageName(opt)
+		if s.hides(opt) {
+			continue
 		}
 		if max < len(opt.uname) && len(opt.uname) <= HelpColumn-3 {
 			max = len(opt.uname)
 		}
 	}
-	// Now print one or more usage lines per option.
+	// Now print one or more usage lines per option, starting with the
+	// options that are not in a section.
+	s.printSection(w, "", max)
+	for _, sec := range s.displaySections() {
+		fmt.Fprintf(w, "\n%s:\n", sec.name)
+		if sec.intro != "" {
+			for _, line := range breakup(sec.intro, DisplayWidth-1) {
+				fmt.Fprintf(w, " %s\n", line)
+			}
+		}
+		s.printSection(w, sec.name, max)
+	}
+}
+
+// printSection prints the usage lines of the options in section name of s to
+// w, with the help messages starting in column max.
+func (s *Set) printSection(w io.Writer, name string, max int) {
 	for _, opt := range s.options {
-		if opt.uname != "" {
+		if opt.uname != "" && !s.hides(opt) && opt.section == name {
 			opt.help = strings.TrimSpace(opt.help)
-			if len(opt.help) == 0 && !opt.mandatory && opt.group == "" {
+			env := s.envUsage(opt)
+			if len(opt.help) == 0 && !opt.mandatory && opt.group == "" && env == "" && opt.deprecation == nil && s.constraintHelp(opt) == "" && (!s.showRanges || rangeHelp(opt) == "") {
 				fmt.Fprintf(w, " %s\n", opt.uname)
 				continue
 			}
-			helpMsg := opt.help
-
-			// If the default value is the known zero value
-			// then don't display it.
-			def := opt.defval
-			switch genericValue(opt.value).(type) {
-			case *bool:
-				if def == "false" {
-					def = ""
-				}
-			case *int, *int8, *int16, *int32, *int64,
-				*uint, *uint8, *uint16, *uint32, *uint64,
-				*float32, *float64:
-				if def == "0" {
-					def = ""
-				}
-			case *time.Duration:
-				if def == "0s" {
-					def = ""
-				}
-			default:
-				if opt.flag && def == "false" {
-					def = ""
-				}
-			}
-			if def != "" {
-				helpMsg += " [" + def + "]"
-			}
-			if opt.group != "" {
-				helpMsg += " {" + opt.group + "}"
-			}
-			if opt.mandatory {
-				helpMsg += " (required)"
-			}
-
-			help := strings.Split(helpMsg, "\n")
+			help := strings.Split(s.helpMessage(opt), "\n")
 			// If they did not put in newlines then we will insert
 			// them to keep the help messages from wrapping.
 			if len(help) == 1 {
@@ -445,11 +686,12 @@ func breakup(s string, max int) []string {
 
 // Parse uses Getopt to parse args using the options set for s.  The first
 // element of args is used to assign the program for s if it is not yet set.  On
-// error, Parse displays the error message as well as a usage message on
-// standard error and then exits the program.
+// error, Parse displays the error message as well as a usage message on the
+// output of s (standard error by default, see SetOutput) and then exits the
+// program.
 func (s *Set) Parse(args []string) {
 	if err := s.Getopt(args, nil); err != nil {
-		fmt.Fprintln(stderr, err)
+		s.printError(err)
 		s.usage()
 		exit(1)
 	}
@@ -461,9 +703,13 @@ func (s *Set) Parse(args []string) {
 //
 // Getopt returns nil when all options have been processed (a non-option
 // argument was encountered, "--" was encountered, or fn returned false).
+// If s is permuting (see SetPermute) then non-option arguments do not stop
+// the processing of options.  The reason Getopt returned is available from
+// State.  Getopt uses a Parser (see Set.Parser) to parse args.
 //
 // On error getopt returns a reference to an InvalidOption (which implements the
-// error interface).
+// error interface).  If s is collecting errors (see SetCollectErrors) then
+// Getopt returns Errors instead.
 func (s *Set) Getopt(args []string, fn func(Option) bool) (err error) {
 	s.setState(InProgress)
 	defer func() {
@@ -479,136 +725,199 @@ func (s *Set) Getopt(args []string, fn func(Option) bool) (err error) {
 		}
 	}()
 
+	// errs are the errors collected when s is collecting errors (see
+	// SetCollectErrors).
+	var errs Errors
 	defer func() {
-		if err == nil {
-			err = s.checkOptions()
+		if !s.collectErrors {
+			if err == nil && !s.skipChecks {
+				err = s.checkOptions()
+				// Running out of arguments is a Failure if the
+				// options are not valid.
+				if err != nil && s.State() == EndOfArguments {
+					s.setState(Failure)
+				}
+			}
+			return
+		}
+		if err != nil {
+			errs = append(errs, asError(err))
+		}
+		if !s.skipChecks {
+			errs = append(errs, s.optionErrors()...)
+		}
+		err = nil
+		if len(errs) > 0 {
+			s.setState(Failure)
+			err = errs
 		}
 	}()
 	if fn == nil {
 		fn = func(Option) bool { return true }
 	}
-	if len(args) == 0 {
+	if len(args) == 0 || s.completionRequest(args) {
 		return nil
 	}
 
-	if s.program == "" {
-		s.program = path.Base(args[0])
-	}
-	args = args[1:]
-Parsing:
-	for len(args) > 0 {
-		arg := args[0]
-		s.args = args
-		args = args[1:]
+	p := s.Parser(args)
 
-		// end of options?
-		if arg == "" || arg[0] != '-' {
-			s.setState(EndOfOptions)
-			return nil
+	// operands are the non-option arguments, in order, and operandIndex
+	// is the index of each in args.
+	operands := []string{}
+	var operandIndex []int
+	for p.Next() {
+		ev := p.Event()
+		switch ev.Kind {
+		case ErrorEvent:
+			if s.collectErrors {
+				errs = append(errs, asError(ev.Err))
+				continue
+			}
+			s.args = p.rest()
+			return ev.Err
+		case OperandEvent:
+			operands = append(operands, ev.Value)
+			operandIndex = append(operandIndex, ev.Index)
+		case OptionEvent:
+			if !fn(ev.Option) {
+				s.args = append(operands, p.rest()...)
+				s.setState(Terminated)
+				// The options are still checked, so they must
+				// first be set from the environment.
+				return s.setEnv()
+			}
 		}
-
-		if arg == "-" {
-			goto ShortParsing
+	}
+	s.args = operands
+	s.setState(p.State())
+	if err := s.setEnv(); err != nil {
+		if !s.collectErrors {
+			return err
 		}
+		errs = append(errs, asError(err))
+	}
+	if len(s.positionals) > 0 {
+		return s.setPositionals(operands, operandIndex)
+	}
+	return nil
+}
 
-		// explicitly request end of options?
-		if arg == "--" {
-			s.args = args
-			s.setState(DashDash)
-			return nil
+// longName returns the long option name in arg, including any =value, and
+// the dash that introduced it ("--" or "-").  It returns "" if arg is not a
+// long option.
+func (s *Set) longName(arg string) (name, dash string) {
+	switch {
+	case len(arg) < 2:
+	case arg[1] == '-':
+		if len(s.longOptions) > 0 {
+			return arg[2:], "--"
 		}
+	case s.singleDash != SingleDashShort:
+		return arg[1:], "-"
+	}
+	return "", ""
+}
 
-		// Long option processing
-		if len(s.longOptions) > 0 && arg[1] == '-' {
-			e := strings.IndexRune(arg, '=')
-			var value string
-			if e > 0 {
-				value = arg[e+1:]
-				arg = arg[:e]
-			}
-			opt := s.longOptions[arg[2:]]
-			// If we are processing long options then --f is -f
-			// if f is not defined as a long option.
-			// This lets you say --f=false
-			if opt == nil && len(arg[2:]) == 1 {
-				opt = s.shortOptions[rune(arg[2])]
-			}
-			if opt == nil {
-				return unknownOption(arg[2:])
+// lookupLong returns the option named by the long option name (without the
+// leading dash).  If name is not a long option but is a single character then
+// the short option of that name is returned.  This lets you say --f=false.  If
+// name is the negated long name of an option then negated is set to name.  If
+// s allows abbreviations then name may also be a unique prefix of a long or
+// negated long name.  Long is set to the long name, alias or negated name that
+// name matched.  Dash is the dash used to introduce the long name and is only
+// used for error messages.
+func (s *Set) lookupLong(name, dash string) (opt *option, long, negated string, err *Error) {
+	if opt := s.longOptions[name]; opt != nil {
+		return opt, name, "", nil
+	}
+	if len(name) == 1 {
+		if opt := s.shortOptions[rune(name[0])]; opt != nil {
+			return opt, "", "", nil
+		}
+	}
+	for _, opt := range s.options {
+		for _, l := range opt.longNames() {
+			if n := s.negationFor(opt).negate(l); n == name {
+				return opt, n, n, nil
 			}
-			opt.isLong = true
-			// If we require an option and did not have an =
-			// then use the next argument as an option.
-			if !opt.flag && e < 0 && !opt.optional {
-				if len(args) == 0 {
-					return missingArg(opt)
-				}
-				value = args[0]
-				args = args[1:]
+		}
+	}
+	if s.abbreviations && name != "" {
+		var candidates []string
+		ambiguous := false
+		s.eachLong(func(l string, o *option, n string) {
+			if !strings.HasPrefix(l, name) {
+				return
 			}
-			opt.count++
-
-			if err := opt.value.Set(value, opt); err != nil {
-				return setError(opt, value, err)
+			candidates = append(candidates, dash+l)
+			if opt != nil && (opt != o || negated != n) {
+				ambiguous = true
 			}
-
-			if !fn(opt) {
-				s.setState(Terminated)
-				return nil
+			// Prefer the long name of the option to its
+			// aliases, and otherwise the first alias.
+			if opt == nil || l == o.long || (long != o.long && l < long) {
+				long = l
 			}
-			continue Parsing
+			opt, negated = o, n
+		})
+		if ambiguous {
+			sort.Strings(candidates)
+			return nil, "", "", ambiguousOption(dash+name, candidates)
+		}
+		if opt != nil {
+			return opt, long, negated, nil
 		}
+	}
+	return nil, "", "", s.suggest(unknownOption(dash + name))
+}
 
-		// Short option processing
-		arg = arg[1:] // strip -
-	ShortParsing:
-		for i, c := range arg {
-			opt := s.shortOptions[c]
-			if opt == nil {
-				// In traditional getopt, if - is not registered
-				// as an option, a lone - is treated as
-				// if there were a -- in front of it.
-				if arg == "-" {
-					s.setState(Dash)
-					return nil
-				}
-				return unknownOption(c)
-			}
-			opt.isLong = false
-			opt.count++
-			var value string
-			if !opt.flag {
-				value = arg[1+i:]
-				if value == "" && !opt.optional {
-					if len(args) == 0 {
-						return missingArg(opt)
-					}
-					value = args[0]
-					args = args[1:]
-				}
-			}
-			if err := opt.value.Set(value, opt); err != nil {
-				return setError(opt, value, err)
-			}
-			if !fn(opt) {
-				s.setState(Terminated)
-				return nil
-			}
-			if !opt.flag {
-				continue Parsing
+// eachLong calls fn with each long name accepted by s and the option it
+// names.  If long is the negated name of opt then negated is also long.
+func (s *Set) eachLong(fn func(long string, opt *option, negated string)) {
+	for long, opt := range s.longOptions {
+		fn(long, opt, "")
+	}
+	for _, opt := range s.options {
+		for _, l := range opt.longNames() {
+			if n := s.negationFor(opt).negate(l); n != "" {
+				fn(n, opt, n)
 			}
 		}
 	}
-	s.args = []string{}
-	return nil
 }
 
+// checkOptions returns the first error returned by optionErrors, if any.
 func (s *Set) checkOptions() error {
+	if errs := s.optionErrors(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// checkParsed returns the errors Getopt returns for the options of s after
+// parsing: all of them as Errors if s is collecting errors, otherwise the
+// first.
+func (s *Set) checkParsed() error {
+	if !s.collectErrors {
+		return s.checkOptions()
+	}
+	if errs := s.optionErrors(); len(errs) > 0 {
+		return Errors(errs)
+	}
+	return nil
+}
+
+// optionErrors returns the errors for the missing mandatory options, the
+// mutually exclusive options, the missing required groups and the constraints
+// of s that are not met, followed by the errors returned by the validators of
+// s.
+func (s *Set) optionErrors() []*Error {
+	var errs []*Error
 	groups := map[string]Option{}
 	for _, opt := range s.options {
-		if !opt.Seen() {
+		if !opt.present() {
 			if opt.mandatory {
-				return fmt.Errorf("option %s is mandatory", opt.Name())
+				errs = append(errs, missingMandatory(opt))
 			}
 			continue
 		}
@@ -616,7 +925,8 @@ func (s *Set) checkOptions() error {
 			continue
 		}
 		if opt2 := groups[opt.group]; opt2 != nil {
-			return fmt.Errorf("options %s and %s are mutually exclusive", opt2.Name(), opt.Name())
+			errs = append(errs, mutuallyExclusive(opt.group, opt2, opt))
+			continue
 		}
 		groups[opt.group] = opt
 	}
@@ -624,13 +934,14 @@ func (s *Set) checkOptions() error {
 		if groups[group] != nil {
 			continue
 		}
-		var flags []string
+		var opts []Option
 		for _, opt := range s.options {
 			if opt.group == group {
-				flags = append(flags, opt.Name())
+				opts = append(opts, opt)
 			}
 		}
-		return fmt.Errorf("exactly one of the following options must be specified: %s", strings.Join(flags, ", "))
+		errs = append(errs, missingRequiredGroup(group, opts))
 	}
-	return nil
+	errs = append(errs, s.constraintErrors()...)
+	return append(errs, s.validatorErrors()...)
 }
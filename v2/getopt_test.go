@@ -5,28 +5,40 @@
 package getopt
 
 import (
+	"bytes"
+	"os"
 	"testing"
 )
 
 func TestMandatory(t *testing.T) {
 	for _, tt := range []struct {
-		name string
-		in   []string
-		err  string
+		name  string
+		in    []string
+		err   string
+		state State
 	}{
 		{
-			name: "required option present",
-			in:   []string{"test", "-r"},
+			name:  "required option present",
+			in:    []string{"test", "-r"},
+			state: EndOfArguments,
 		},
 		{
-			name: "required option not present",
-			in:   []string{"test", "-o"},
-			err:  "test: option -r is mandatory",
+			name:  "required option not present",
+			in:    []string{"test", "-o"},
+			err:   "test: option -r is mandatory",
+			state: Failure,
 		},
 		{
-			name: "no options",
-			in:   []string{"test"},
-			err:  "test: option -r is mandatory",
+			name:  "no options",
+			in:    []string{"test"},
+			err:   "test: option -r is mandatory",
+			state: Failure,
+		},
+		{
+			name:  "operands",
+			in:    []string{"test", "file"},
+			err:   "test: option -r is mandatory",
+			state: EndOfOptions,
 		},
 	} {
 		reset()
@@ -37,6 +49,9 @@ func TestMandatory(t *testing.T) {
 		if s := checkError(tt.err); s != "" {
 			t.Errorf("%s: %s", tt.name, s)
 		}
+		if got := CommandLine.State(); got != tt.state {
+			t.Errorf("%s: got state %v, want %v", tt.name, got, tt.state)
+		}
 	}
 }
 
@@ -80,3 +95,380 @@ func TestGroup(t *testing.T) {
 		}
 	}
 }
+
+func TestPermute(t *testing.T) {
+	if v, ok := os.LookupEnv("POSIXLY_CORRECT"); ok {
+		defer os.Setenv("POSIXLY_CORRECT", v)
+		os.Unsetenv("POSIXLY_CORRECT")
+	}
+	for _, tt := range []struct {
+		name   string
+		in     []string
+		strict bool
+		posix  bool
+		args   []string
+		state  State
+		v      bool
+		err    string
+	}{
+		{
+			name:  "no args",
+			in:    []string{"test"},
+			state: EndOfArguments,
+		},
+		{
+			name:  "mixed",
+			in:    []string{"test", "file1", "-v", "file2"},
+			args:  []string{"file1", "file2"},
+			state: EndOfArguments,
+			v:     true,
+		},
+		{
+			name:  "dash is an operand",
+			in:    []string{"test", "-", "-v"},
+			args:  []string{"-"},
+			state: EndOfArguments,
+			v:     true,
+		},
+		{
+			name:  "dash dash",
+			in:    []string{"test", "file1", "--", "-v", "file2"},
+			args:  []string{"file1", "-v", "file2"},
+			state: DashDash,
+		},
+		{
+			name:   "strict",
+			in:     []string{"test", "file1", "-v", "file2"},
+			strict: true,
+			args:   []string{"file1", "-v", "file2"},
+			state:  EndOfOptions,
+		},
+		{
+			name:  "POSIXLY_CORRECT",
+			in:    []string{"test", "file1", "-v", "file2"},
+			posix: true,
+			args:  []string{"file1", "-v", "file2"},
+			state: EndOfOptions,
+		},
+		{
+			name:  "error",
+			in:    []string{"test", "file1", "-x", "file2"},
+			args:  []string{"-x", "file2"},
+			state: Failure,
+			err:   "test: unknown option: -x",
+		},
+	} {
+		reset()
+		var v bool
+		Flag(&v, 'v')
+		SetPermute(true)
+		SetPosixlyCorrect(tt.strict)
+		if tt.posix {
+			os.Setenv("POSIXLY_CORRECT", "1")
+		}
+		parse(tt.in)
+		os.Unsetenv("POSIXLY_CORRECT")
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.name, s)
+		}
+		if badSlice(Args(), tt.args) {
+			t.Errorf("%s: got args %q, want %q", tt.name, Args(), tt.args)
+		}
+		if got := CommandLine.State(); got != tt.state {
+			t.Errorf("%s: got state %v, want %v", tt.name, got, tt.state)
+		}
+		if v != tt.v {
+			t.Errorf("%s: got v %v, want %v", tt.name, v, tt.v)
+		}
+	}
+}
+
+func TestAbbreviations(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		in      []string
+		verbose bool
+		version bool
+		v       bool
+		err     string
+	}{
+		{
+			name:    "unique prefix",
+			in:      []string{"test", "--verb"},
+			verbose: true,
+		},
+		{
+			name:    "exact match",
+			in:      []string{"test", "--version"},
+			version: true,
+		},
+		{
+			name: "exact match wins",
+			in:   []string{"test", "--v"},
+			v:    true,
+		},
+		{
+			name: "ambiguous",
+			in:   []string{"test", "--ver"},
+			err:  "test: ambiguous option: --ver could be --verbose, --version",
+		},
+		{
+			name: "unknown",
+			in:   []string{"test", "--x"},
+			err:  "test: unknown option: --x",
+		},
+	} {
+		reset()
+		var verbose, version, v bool
+		FlagLong(&verbose, "verbose", 0)
+		FlagLong(&version, "version", 0)
+		Flag(&v, 'v')
+		SetAbbreviations(true)
+		parse(tt.in)
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.name, s)
+		}
+		if verbose != tt.verbose || version != tt.version || v != tt.v {
+			t.Errorf("%s: got %v %v %v, want %v %v %v", tt.name, verbose, version, v, tt.verbose, tt.version, tt.v)
+		}
+	}
+
+	reset()
+	var verbose, version bool
+	FlagLong(&verbose, "verbose", 0)
+	FlagLong(&version, "version", 0)
+	SetAbbreviations(true)
+	err := CommandLine.Getopt([]string{"test", "--ve"}, nil)
+	e, ok := err.(*Error)
+	if !ok || e.ErrorCode != AmbiguousOption {
+		t.Fatalf("got error %v, want AmbiguousOption", err)
+	}
+	if want := []string{"--verbose", "--version"}; badSlice(e.Candidates, want) {
+		t.Errorf("got candidates %q, want %q", e.Candidates, want)
+	}
+}
+
+func TestSingleDash(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		mode  SingleDashMode
+		in    []string
+		v, x  bool
+		str   string
+		args  []string
+		err   string
+		names []string // the names of the occurrences
+	}{
+		{
+			name: "long name",
+			mode: SingleDashLong,
+			in:   []string{"test", "-name", "value", "arg"},
+			str:  "value",
+			args: []string{"arg"},
+		},
+		{
+			name: "long name with =",
+			mode: SingleDashLong,
+			in:   []string{"test", "-name=value"},
+			str:  "value",
+		},
+		{
+			name: "double dash still works",
+			mode: SingleDashLong,
+			in:   []string{"test", "--name=value"},
+			str:  "value",
+		},
+		{
+			name: "long flag",
+			mode: SingleDashLong,
+			in:   []string{"test", "-verbose"},
+			v:    true,
+		},
+		{
+			name: "fallback to short options",
+			mode: SingleDashLong,
+			in:   []string{"test", "-vx"},
+			v:    true,
+			x:    true,
+		},
+		{
+			name: "fallback to short value",
+			mode: SingleDashLong,
+			in:   []string{"test", "-nvalue"},
+			str:  "value",
+		},
+		{
+			name: "short mode",
+			mode: SingleDashShort,
+			in:   []string{"test", "-name"},
+			str:  "ame",
+		},
+		{
+			name: "strict short option",
+			mode: SingleDashStrict,
+			in:   []string{"test", "-v", "-x"},
+			v:    true,
+			x:    true,
+		},
+		{
+			name: "strict no clustering",
+			mode: SingleDashStrict,
+			in:   []string{"test", "-vx"},
+			err:  "test: unknown option: -vx",
+		},
+		{
+			name:  "names used",
+			mode:  SingleDashLong,
+			in:    []string{"test", "-name", "value", "--verbose", "-x"},
+			v:     true,
+			x:     true,
+			str:   "value",
+			names: []string{"-name", "--verbose", "-x"},
+		},
+		{
+			name:  "strict names used",
+			mode:  SingleDashStrict,
+			in:    []string{"test", "-verbose", "-n", "value"},
+			v:     true,
+			str:   "value",
+			names: []string{"-verbose", "-n"},
+		},
+		{
+			name: "missing parameter",
+			mode: SingleDashLong,
+			in:   []string{"test", "-name"},
+			err:  "test: missing parameter for -name",
+		},
+		{
+			name: "missing parameter with double dash",
+			mode: SingleDashLong,
+			in:   []string{"test", "--name"},
+			err:  "test: missing parameter for --name",
+		},
+	} {
+		reset()
+		var v, x bool
+		var str string
+		FlagLong(&v, "verbose", 'v')
+		Flag(&x, 'x')
+		FlagLong(&str, "name", 'n')
+		SetSingleDash(tt.mode)
+		parse(tt.in)
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.name, s)
+		}
+		if tt.err != "" {
+			continue
+		}
+		if v != tt.v || x != tt.x || str != tt.str {
+			t.Errorf("%s: got %v %v %q, want %v %v %q", tt.name, v, x, str, tt.v, tt.x, tt.str)
+		}
+		if badSlice(Args(), tt.args) {
+			t.Errorf("%s: got args %q, want %q", tt.name, Args(), tt.args)
+		}
+		if tt.names == nil {
+			continue
+		}
+		var names []string
+		for _, occ := range Occurrences() {
+			names = append(names, occ.Name)
+		}
+		if badSlice(names, tt.names) {
+			t.Errorf("%s: got names %q, want %q", tt.name, names, tt.names)
+		}
+	}
+}
+
+func TestSingleDashUsage(t *testing.T) {
+	for _, tt := range []struct {
+		mode  SingleDashMode
+		usage string
+		opts  string
+	}{
+		{
+			mode:  SingleDashShort,
+			usage: "[-vx] [--debug] [-n value]",
+			opts: `
+     --debug       debug
+ -n, --name=value  the name
+ -v, --verbose     be verbose
+ -x                x marks the spot
+`[1:],
+		},
+		{
+			mode:  SingleDashLong,
+			usage: "[-vx] [-debug] [-n value]",
+			opts: `
+     -debug       debug
+ -n, -name=value  the name
+ -v, -verbose     be verbose
+ -x               x marks the spot
+`[1:],
+		},
+		{
+			mode:  SingleDashStrict,
+			usage: "[-v] [-x] [-debug] [-n value]",
+			opts: `
+     -debug       debug
+ -n, -name=value  the name
+ -v, -verbose     be verbose
+ -x               x marks the spot
+`[1:],
+		},
+	} {
+		reset()
+		var v, x, debug bool
+		var str string
+		FlagLong(&v, "verbose", 'v', "be verbose")
+		Flag(&x, 'x', "x marks the spot")
+		FlagLong(&debug, "debug", 0, "debug")
+		FlagLong(&str, "name", 'n', "the name")
+		SetSingleDash(tt.mode)
+		if got := CommandLine.UsageLine(); got != tt.usage {
+			t.Errorf("%d: got usage %q, want %q", tt.mode, got, tt.usage)
+		}
+		var buf bytes.Buffer
+		CommandLine.PrintOptions(&buf)
+		if got := buf.String(); got != tt.opts {
+			t.Errorf("%d: got:\n%s\nwant:\n%s", tt.mode, got, tt.opts)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	reset()
+	var level int
+	var v bool
+	FlagLong(&level, "level", 'l')
+	Flag(&v, 'v')
+	parse([]string{"test", "--level", "3", "-v", "-l7", "--level=5"})
+	if errorString != "" {
+		t.Fatal(errorString)
+	}
+	want := []Occurrence{
+		{Name: "--level", Value: "3", Index: 1, Long: true},
+		{Name: "-v", Index: 3},
+		{Name: "-l", Value: "7", Index: 4},
+		{Name: "--level", Value: "5", Index: 5, Long: true},
+	}
+	check := func(name string, got []Occurrence, want []Occurrence) {
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d occurrences, want %d", name, len(got), len(want))
+		}
+		for x, occ := range got {
+			if occ.Option == nil {
+				t.Errorf("%s #%d: no option", name, x)
+			}
+			occ.Option = nil
+			if occ != want[x] {
+				t.Errorf("%s #%d: got %+v, want %+v", name, x, occ, want[x])
+			}
+		}
+	}
+	check("set", Occurrences(), want)
+	check("level", Lookup("level").Occurrences(), []Occurrence{want[0], want[2], want[3]})
+
+	Reset()
+	check("reset set", Occurrences(), nil)
+	check("reset level", Lookup("level").Occurrences(), nil)
+}
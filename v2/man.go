@@ -0,0 +1,200 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A ManPage describes the parts of a man page that are not derived from the
+// options of a Set.  The text of Description, Environment and ExitStatus is
+// filled, with blank lines separating paragraphs.  The text of Examples is
+// displayed as is.
+type ManPage struct {
+	Name        string // name of the program, defaults to the program of the Set
+	Summary     string // one line summary, for the NAME section
+	Date        string // date of the page, such as "January 2017"
+	Source      string // source of the program, such as "myprog 1.2"
+	Manual      string // title of the manual, such as "User Commands"
+	Description string
+	Environment string
+	ExitStatus  string
+	Examples    string
+}
+
+// WriteManPage calls WriteManPage on the command line options.
+func WriteManPage(w io.Writer, m *ManPage) error {
+	return CommandLine.WriteManPage(w, m)
+}
+
+// WriteManPage writes a section 1 man page for the options of s to w in roff
+// format.  The page has the sections NAME, SYNOPSIS, DESCRIPTION, OPTIONS,
+// ENVIRONMENT, EXIT STATUS and EXAMPLES, leaving out any that are empty.  The
+// SYNOPSIS is built from UsageLine and the parameters of s, and OPTIONS lists
+// the options of s with their help messages, as shown by PrintOptions.  The
+// output only depends on s and m, so it can be checked in and compared.
+func (s *Set) WriteManPage(w io.Writer, m *ManPage) error {
+	if m == nil {
+		m = &ManPage{}
+	}
+	name := m.Name
+	if name == "" {
+		name = s.program
+	}
+	if name == "" {
+		return fmt.Errorf("program name not set")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, ".TH %s 1 %s %s %s\n", roffQuote(strings.ToUpper(name)), roffQuote(m.Date), roffQuote(m.Source), roffQuote(m.Manual))
+
+	b.WriteString(".SH NAME\n")
+	if m.Summary != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(name), roffEscape(m.Summary))
+	} else {
+		fmt.Fprintf(&b, "%s\n", roffEscape(name))
+	}
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, ".B %s\n", roffEscape(name))
+	var synopsis []string
+	if usage := s.UsageLine(); usage != "" {
+		synopsis = append(synopsis, usage)
+	}
+	if s.parameters != "" {
+		synopsis = append(synopsis, s.parameters)
+	}
+	if len(synopsis) > 0 {
+		fmt.Fprintf(&b, "%s\n", roffLine(strings.Join(synopsis, " ")))
+	}
+
+	writeManText(&b, "DESCRIPTION", m.Description)
+
+	sort.Sort(s.options)
+	sections := s.displaySections()
+	for _, opt := range s.options {
+		if !opt.hidden {
+			b.WriteString(".SH OPTIONS\n")
+			break
+		}
+	}
+	s.writeManOptions(&b, "")
+	for _, sec := range sections {
+		fmt.Fprintf(&b, ".SS %s\n", roffLine(sec.name))
+		if sec.intro != "" {
+			fmt.Fprintf(&b, "%s\n", roffLine(sec.intro))
+		}
+		s.writeManOptions(&b, sec.name)
+	}
+
+	writeManText(&b, "ENVIRONMENT", m.Environment)
+	writeManText(&b, "EXIT STATUS", m.ExitStatus)
+	if text := strings.Trim(m.Examples, "\n"); text != "" {
+		b.WriteString(".SH EXAMPLES\n.nf\n")
+		for _, line := range strings.Split(text, "\n") {
+			fmt.Fprintf(&b, "%s\n", roffLine(line))
+		}
+		b.WriteString(".fi\n")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeManOptions writes the options of s in section name to b.
+func (s *Set) writeManOptions(b *bytes.Buffer, name string) {
+	for _, opt := range s.options {
+		if opt.hidden || opt.section != name {
+			continue
+		}
+		fmt.Fprintf(b, ".TP\n%s\n", s.manName(opt))
+		for x, line := range strings.Split(strings.TrimSpace(s.helpMessage(opt)), "\n") {
+			if x > 0 {
+				b.WriteString(".br\n")
+			}
+			fmt.Fprintf(b, "%s\n", roffLine(strings.TrimSpace(line)))
+		}
+	}
+}
+
+// manName returns the names of opt and its value in roff, such as
+// "\fB\-o\fR, \fB\-\-output\fR=\fIfile\fR".
+func (s *Set) manName(opt *option) string {
+	shorts, longs := []rune{opt.short}, []string{opt.long}
+	if s.showAliases {
+		shorts, longs = opt.shortNames(), opt.longNames()
+	}
+	var names []string
+	for _, c := range shorts {
+		if c != 0 {
+			names = append(names, `\fB`+roffEscape("-"+string(c))+`\fR`)
+		}
+	}
+	for _, l := range longs {
+		if l == opt.long {
+			l = s.negationFor(opt).usage(l)
+		}
+		if l != "" {
+			names = append(names, `\fB`+roffEscape(s.longDash()+l)+`\fR`)
+		}
+	}
+	n := strings.Join(names, ", ")
+	value := `\fI` + roffEscape(opt.name) + `\fR`
+	switch {
+	case opt.flag:
+		return n
+	case opt.optional:
+		return n + "[=" + value + "]"
+	case opt.long != "":
+		return n + "=" + value
+	}
+	return n + " " + value
+}
+
+// writeManText writes the section title with text, filled in paragraphs
+// separated by blank lines, to b.  Nothing is written if text is empty.
+func writeManText(b *bytes.Buffer, title, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(b, ".SH %s\n", title)
+	for x, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if x > 0 {
+			b.WriteString(".PP\n")
+		}
+		for _, line := range strings.Split(para, "\n") {
+			fmt.Fprintf(b, "%s\n", roffLine(strings.TrimSpace(line)))
+		}
+	}
+}
+
+// roffEscape escapes the characters in s that are special to roff.
+func roffEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	return strings.Replace(s, "-", `\-`, -1)
+}
+
+// roffLine returns s escaped as a line of text, which must not start with a
+// control character.
+func roffLine(s string) string {
+	s = roffEscape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// roffQuote returns s escaped as a quoted argument of a request.
+func roffQuote(s string) string {
+	return `"` + strings.Replace(roffEscape(s), `"`, `\(dq`, -1) + `"`
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestManPage(t *testing.T) {
+	s := New()
+	s.SetProgram("myprog")
+	s.SetParameters("file ...")
+	var (
+		alpha, beta bool
+		count       int
+	)
+	s.BoolLong("verbose", 'v', "be verbose")
+	s.StringLong("output", 'o', "-", "write output to file\n(- is standard output)", "file")
+	s.FlagLong(&count, "count", 'c', "number of copies").Mandatory()
+	s.FlagLong(&alpha, "alpha", 'a', "use alpha").SetGroup("method")
+	s.FlagLong(&beta, "beta", 0, "use beta").SetGroup("method")
+	s.AddCompletionOption()
+
+	var b bytes.Buffer
+	if err := s.WriteManPage(&b, &ManPage{
+		Summary:     "copy files",
+		Date:        "January 2017",
+		Source:      "myprog 1.0",
+		Manual:      "User Commands",
+		Description: "Myprog copies files.\n\n.Hidden files are copied too.",
+		Environment: "MYPROG_DIR is the default directory.",
+		ExitStatus:  "0 on success, 1 on failure.",
+		Examples:    "  myprog -c 2 file\n  myprog -c 1 -o out file",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := `.TH "MYPROG" 1 "January 2017" "myprog 1.0" "User Commands"
+.SH NAME
+myprog \- copy files
+.SH SYNOPSIS
+.B myprog
+[\-av] [\-\-beta] [\-c value] [\-o file] file ...
+.SH DESCRIPTION
+Myprog copies files.
+.PP
+\&.Hidden files are copied too.
+.SH OPTIONS
+.TP
+\fB\-a\fR, \fB\-\-alpha\fR
+use alpha {method}
+.TP
+\fB\-\-beta\fR
+use beta {method}
+.TP
+\fB\-c\fR, \fB\-\-count\fR=\fIvalue\fR
+number of copies (required)
+.TP
+\fB\-o\fR, \fB\-\-output\fR=\fIfile\fR
+write output to file
+.br
+(\- is standard output) [\-]
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+be verbose
+.SH ENVIRONMENT
+MYPROG_DIR is the default directory.
+.SH EXIT STATUS
+0 on success, 1 on failure.
+.SH EXAMPLES
+.nf
+  myprog \-c 2 file
+  myprog \-c 1 \-o out file
+.fi
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Only the sections derived from the options are written by default.
+	b.Reset()
+	s = New()
+	s.SetProgram("prog")
+	if err := s.WriteManPage(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	want = `.TH "PROG" 1 "" "" ""
+.SH NAME
+prog
+.SH SYNOPSIS
+.B prog
+[parameters ...]
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := New().WriteManPage(&b, nil); err == nil {
+		t.Errorf("no program: did not get an error")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import "strings"
+
+// A negation describes how the long name of an option is negated.  A long
+// name that begins with on is negated by replacing on with off.  Long names
+// are not negated if on and off are the same.
+type negation struct {
+	on  string
+	off string
+}
+
+// newNegation returns the negation described by prefix.  No prefix is the
+// same as passing "no-".  A single prefix is the negating prefix, for example
+// "no-" for --no-color.  Two prefixes are the positive and negating prefixes,
+// for example "enable-" and "disable-" for --enable-x and --disable-x.  Equal
+// prefixes, such as the single prefix "", describe turning off negation.
+func newNegation(prefix []string) *negation {
+	n := &negation{off: "no-"}
+	switch len(prefix) {
+	case 0:
+	case 1:
+		n.off = prefix[0]
+	case 2:
+		n.on, n.off = prefix[0], prefix[1]
+	default:
+		panic("too many prefixes for negation")
+	}
+	return n
+}
+
+// negate returns the negated form of long.  It returns "" if long cannot be
+// negated.
+func (n *negation) negate(long string) string {
+	if n == nil || n.on == n.off || long == "" || !strings.HasPrefix(long, n.on) {
+		return ""
+	}
+	return n.off + long[len(n.on):]
+}
+
+// usage returns the long name long as displayed in the usage, such as
+// "[no-]color" or "[enable|disable]-x".
+func (n *negation) usage(long string) string {
+	if n.negate(long) == "" {
+		return long
+	}
+	if n.on == "" {
+		return "[" + n.off + "]" + long
+	}
+	on, off, sep := n.on, n.off, ""
+	if strings.HasSuffix(on, "-") && strings.HasSuffix(off, "-") {
+		on, off, sep = on[:len(on)-1], off[:len(off)-1], "-"
+	}
+	return "[" + on + "|" + off + "]" + sep + long[len(n.on):]
+}
+
+// negatable returns true if o is an option that can be negated: a flag or
+// a list with a long name.
+func (o *option) negatable() bool {
+	if o.long == "" {
+		return false
+	}
+	if o.flag {
+		return true
+	}
+	_, ok := genericValue(o.value).(*[]string)
+	return ok
+}
+
+func (o *option) Negatable(prefix ...string) Option {
+	o.negation = newNegation(prefix)
+	return o
+}
+
+// negate sets o to its negated value.  Flags are set to false, counters are
+// set to 0 and lists are set to empty.
+func (o *option) negate() error {
+	if p, ok := o.value.(*counterValue); ok {
+		*p = 0
+		return nil
+	}
+	if p, ok := genericValue(o.value).(*[]string); ok {
+		*p = nil
+		return nil
+	}
+	return o.value.Set("false", o)
+}
+
+// SetNegatable calls SetNegatable on the command line options.
+func SetNegatable(prefix ...string) {
+	CommandLine.SetNegatable(prefix...)
+}
+
+// SetNegatable makes all flags and lists in s that have a long name negatable
+// (see Option.Negatable) using prefix, unless the option has its own
+// negation.  Passing the single prefix "" turns off negation for s.
+func (s *Set) SetNegatable(prefix ...string) {
+	s.negation = newNegation(prefix)
+}
+
+// negationFor returns the negation used by opt in s, or nil if opt is not a
+// negatable option.  The negation of opt takes precedence over that of s,
+// even if it turns off negation.
+func (s *Set) negationFor(opt *option) *negation {
+	if !opt.negatable() {
+		return nil
+	}
+	if opt.negation != nil {
+		return opt.negation
+	}
+	return s.negation
+}
@@ -0,0 +1,173 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"testing"
+)
+
+var negateTests = []struct {
+	where string
+	in    []string
+	color bool
+	x     bool
+	y     bool
+	list  []string
+	name  string
+	err   string
+}{
+	{
+		loc(),
+		[]string{"test", "--color"},
+		true, true, true, []string{"d"},
+		"--color",
+		"",
+	},
+	{
+		loc(),
+		[]string{"test", "--color", "--no-color"},
+		false, true, true, []string{"d"},
+		"--no-color",
+		"",
+	},
+	{
+		loc(),
+		[]string{"test", "--no-color=true"},
+		true, true, true, []string{"d"},
+		"--no-color",
+		"test: unexpected parameter passed to --no-color: \"true\"\n",
+	},
+	{
+		loc(),
+		[]string{"test", "--disable-x"},
+		true, false, true, []string{"d"},
+		"--color",
+		"",
+	},
+	{
+		loc(),
+		[]string{"test", "--without-y"},
+		true, true, false, []string{"d"},
+		"--color",
+		"",
+	},
+	{
+		loc(),
+		[]string{"test", "--list=a,b", "--no-list", "--list=c"},
+		true, true, true, []string{"c"},
+		"--color",
+		"",
+	},
+	{
+		loc(),
+		[]string{"test", "--no-list"},
+		true, true, true, nil,
+		"--color",
+		"",
+	},
+	{
+		loc(),
+		[]string{"test", "--no-enable-x"},
+		true, true, true, []string{"d"},
+		"--color",
+		"test: unknown option: --no-enable-x\n",
+	},
+}
+
+func TestNegatable(t *testing.T) {
+	for _, tt := range negateTests {
+		reset()
+		color, x, y := true, true, true
+		list := []string{"d"}
+		SetNegatable()
+		FlagLong(&color, "color", 0)
+		FlagLong(&x, "enable-x", 0).Negatable("enable-", "disable-")
+		FlagLong(&y, "with-y", 0).Negatable("with-", "without-")
+		FlagLong(&list, "list", 0)
+
+		parse(tt.in)
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.where, s)
+		}
+		if color != tt.color || x != tt.x || y != tt.y {
+			t.Errorf("%s: got %v %v %v, want %v %v %v", tt.where, color, x, y, tt.color, tt.x, tt.y)
+		}
+		if badSlice(list, tt.list) {
+			t.Errorf("%s: got list %q, want %q", tt.where, list, tt.list)
+		}
+		if got := Lookup("color").Name(); got != tt.name {
+			t.Errorf("%s: got name %q, want %q", tt.where, got, tt.name)
+		}
+	}
+}
+
+func TestNegatableUsage(t *testing.T) {
+	HelpColumn = 40
+	reset()
+	var color, x, v bool
+	FlagLong(&color, "color", 0, "use color").Negatable()
+	FlagLong(&x, "enable-x", 0, "enable x").Negatable("enable-", "disable-")
+	FlagLong(&v, "verbose", 'v', "be verbose")
+	want := `
+     --[no-]color          use color
+     --[enable|disable]-x  enable x
+ -v, --verbose             be verbose
+`[1:]
+	var buf bytes.Buffer
+	CommandLine.PrintOptions(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := CommandLine.UsageLine(), "[-v] [--[no-]color] [--[enable|disable]-x]"; got != want {
+		t.Errorf("got usage %q, want %q", got, want)
+	}
+}
+
+func TestNegatableCounter(t *testing.T) {
+	for _, tt := range []struct {
+		where   string
+		in      []string
+		verbose int
+	}{
+		{loc(), []string{"test", "-vv"}, 2},
+		{loc(), []string{"test", "-vv", "--no-verbose"}, 0},
+		{loc(), []string{"test", "-vv", "--no-verbose", "-v"}, 1},
+		{loc(), []string{"test", "--verbose=5", "--no-verbose"}, 0},
+	} {
+		reset()
+		SetNegatable()
+		verbose := CounterLong("verbose", 'v')
+		parse(tt.in)
+		if s := checkError(""); s != "" {
+			t.Errorf("%s: %s", tt.where, s)
+		}
+		if *verbose != tt.verbose {
+			t.Errorf("%s: got verbose %d, want %d", tt.where, *verbose, tt.verbose)
+		}
+	}
+}
+
+func TestNegatableOff(t *testing.T) {
+	HelpColumn = 20
+	reset()
+	SetNegatable()
+	var color, beta bool
+	FlagLong(&color, "color", 0, "use color")
+	FlagLong(&beta, "beta", 0, "use beta").Negatable("")
+	parse([]string{"test", "--beta", "--no-beta"})
+	if s := checkError("test: unknown option: --no-beta\n"); s != "" {
+		t.Error(s)
+	}
+	want := `
+     --beta        use beta
+     --[no-]color  use color
+`[1:]
+	var buf bytes.Buffer
+	CommandLine.PrintOptions(&buf)
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -48,8 +48,9 @@ type Option interface {
 	// always a single argument.  Either --option or --option=value.  In
 	// the former case the value of the option does not change but the Set()
 	// will return true and the value returned by Count() is incremented.
-	// The short form is either -o or -ovalue.  SetOptional returns
-	// the Option
+	// The short form is either -o or -ovalue.  For a positional
+	// parameter (see Set.Positional), SetOptional makes the parameter
+	// optional.  SetOptional returns the Option
 	SetOptional() Option
 
 	// SetFlag makes the value a flag.  Flags are boolean values and
@@ -71,27 +72,146 @@ type Option interface {
 	// SetGroup sets the option as part of a radio group.  Parse will
 	// fail if two options in the same group are seen.
 	SetGroup(string) Option
+
+	// Negatable allows the long name of a flag or list option to be
+	// negated.  Negating a flag sets it to false, negating a counter sets
+	// it to 0 and negating a list sets it to empty.  With no prefix the
+	// negated form of --color is --no-color.  A single prefix replaces
+	// "no-".  Two prefixes are a positive and negative pair, e.g.,
+	// "enable-" and "disable-" allow --disable-x to negate --enable-x.
+	// The single prefix "" turns off negation of the option, even if it
+	// is turned on for its set (see SetNegatable).  Negatable has no
+	// effect on options that are not flags or lists or that have no long
+	// name.  Negatable returns the Option.
+	Negatable(prefix ...string) Option
+
+	// Env binds the option to the environment variables names.  If the
+	// option is not seen while parsing then it is set from the first of
+	// the variables that is set to a non-empty value.  An option set from
+	// the environment satisfies Mandatory and RequiredGroup, but its Seen
+	// and Count do not change.  Env overrides Set.SetEnvPrefix, and
+	// calling Env with no names unbinds the option from the prefix.  Env
+	// returns the Option.
+	Env(names ...string) Option
+
+	// Occurrences returns each use of the option, in order, since it was
+	// last reset.
+	Occurrences() []Occurrence
+
+	// SetVariadic makes a positional parameter (see Set.Positional)
+	// consume all the remaining arguments.  Only the last positional
+	// parameter may be variadic.  SetVariadic returns the Option.
+	SetVariadic() Option
+
+	// SetCompleter sets the function used to complete the value of the
+	// option for dynamic shell completion (see SetDynamicCompletion).
+	// SetCompleter returns the Option.
+	SetCompleter(Completer) Option
+
+	// SetSection places the option in the display section name (see
+	// Set.AddSection) and returns the Option.
+	SetSection(name string) Option
+
+	// Hidden hides the option.  A hidden option is parsed as usual but
+	// is not displayed by UsageLine or PrintOptions, other than by
+	// PrintUsageAll, and is not completed.  Hidden returns the Option.
+	Hidden() Option
+
+	// Deprecated marks the option as deprecated.  The first time the
+	// option is used a warning, including msg if not empty, is written
+	// to the output of the Set (see SetOutput) and the deprecation hook
+	// of the Set is called (see SetDeprecationHook).  If a replacement
+	// option is given, the values of the option are set on the
+	// replacement instead, which is then seen rather than the option.
+	// Deprecated returns the Option.
+	Deprecated(msg string, replacement ...Option) Option
+
+	// Alias adds the long names to the option as aliases of its long
+	// name.  Alias returns the Option.
+	Alias(long ...string) Option
+
+	// ShortAlias adds the short names to the option as aliases of its
+	// short name.  ShortAlias returns the Option.
+	ShortAlias(short ...rune) Option
+
+	// Requires requires the options to be used whenever the option is
+	// used.  Requires returns the Option.
+	Requires(opts ...Option) Option
+
+	// ConflictsWith prohibits the options from being used along with
+	// the option.  ConflictsWith returns the Option.
+	ConflictsWith(opts ...Option) Option
+
+	// RequiredIf requires the option to be used if pred returns true for
+	// other after parsing, such as when other has a particular value
+	// (see HasValue).  RequiredIf returns the Option.
+	RequiredIf(other Option, pred func(Option) bool) Option
+
+	// ForbiddenIf prohibits the option from being used if pred returns
+	// true for other after parsing.  ForbiddenIf returns the Option.
+	ForbiddenIf(other Option, pred func(Option) bool) Option
+
+	// Validate adds validators, such as Range, NonEmpty, Regexp or
+	// Finite, that are called each time the option is set while parsing.
+	// Validate returns the Option.
+	Validate(validators ...Validator) Option
+}
+
+// An Occurrence records a single use of an option while parsing.
+type Occurrence struct {
+	Option Option // The option that was used
+	Name   string // The name used, such as "-v" or "--verbose"
+	Value  string // The value passed to the option, if any
+	Index  int    // The index of the argument the option was found in
+	Long   bool   // True if the long name was used
 }
 
 type option struct {
-	short     rune   // 0 means no short name
-	long      string // "" means no long name
-	isLong    bool   // True if they used the long name
-	flag      bool   // true if a boolean flag
-	defval    string // default value
-	optional  bool   // true if we take an optional value
-	help      string // help message
-	where     string // file where the option was defined
-	value     Value  // current value of option
-	count     int    // number of times we have seen this option
-	name      string // name of the value (for usage)
-	uname     string // name of the option (for usage)
-	mandatory bool   // this option must be specified
-	group     string // mutual exclusion group
-}
-
-// usageName returns the name of the option for printing usage lines in one
-// of the following forms:
+	short     rune      // 0 means no short name
+	long      string    // "" means no long name
+	isLong    bool      // True if they used the long name
+	flag      bool      // true if a boolean flag
+	defval    string    // default value
+	optional  bool      // true if we take an optional value
+	help      string    // help message
+	where     string    // file where the option was defined
+	value     Value     // current value of option
+	count     int       // number of times we have seen this option
+	name      string    // name of the value (for usage)
+	uname     string    // name of the option (for usage)
+	mandatory bool      // this option must be specified
+	group     string    // mutual exclusion group
+	section   string    // display section
+	hidden    bool      // not listed in usage or completions
+	negation  *negation // how to negate the long name, if not nil
+	negated   string    // the negated long name, if last used
+	alias     string    // the alias, with dashes, if last used
+	dash      string    // the dash the long name was last used with
+
+	occurrences []Occurrence // each use of this option
+
+	env    []string // environment variables bound to this option
+	envSet string   // the environment variable this option was set from
+	config string   // the configuration this option was set from
+
+	positional bool // true if this is a positional parameter
+	variadic   bool // true if the positional parameter takes all arguments
+	set        *Set // the set the option was first added to
+
+	completer Completer // completes the value of the option
+
+	deprecation *deprecation // set if the option is deprecated
+
+	longAliases  []string // aliases of the long name
+	shortAliases []rune   // aliases of the short name
+
+	constraints []*constraint // constraints declared on the option
+	validators  []Validator   // validate each value the option is set to
+}
+
+// usageName returns the name of the option o in s for printing usage lines in
+// one of the following forms (with a single dash for long options if s is in
+// a SingleDash mode):
 //
 //  -f
 //      --flag
@@ -99,21 +219,38 @@ type option struct {
 //  -s value
 //      --set=value
 //  -s, --set=value
-func (o *option) usageName() string {
+func (s *Set) usageName(o *option) string {
 	// Don't print help messages if we have none and there is only one
 	// way to specify the option.
 	if o.help == "" && (o.short == 0 || o.long == "") {
 		return ""
 	}
 	n := ""
+	dash := s.longDash()
+	long := s.negationFor(o).usage(o.long)
 
 	switch {
+	case s.showAliases && len(o.shortAliases)+len(o.longAliases) > 0:
+		var names []string
+		for _, c := range o.shortNames() {
+			names = append(names, "-"+string(c))
+		}
+		for _, l := range o.longNames() {
+			if l == o.long {
+				l = long
+			}
+			names = append(names, dash+l)
+		}
+		n = strings.Join(names, ", ")
+		if o.short == 0 && len(o.shortAliases) == 0 {
+			n = "    " + n
+		}
 	case o.short != 0 && o.long == "":
 		n = "-" + string(o.short)
 	case o.short == 0 && o.long != "":
-		n = "    --" + o.long
+		n = "    " + dash + long
 	case o.short != 0 && o.long != "":
-		n = "-" + string(o.short) + ", --" + o.long
+		n = "-" + string(o.short) + ", " + dash + long
 	}
 
 	switch {
@@ -135,15 +272,32 @@ func (o *option) sortName() string {
 	return o.long[:1] + o.long
 }
 
-func (o *option) Seen() bool               { return o.count > 0 }
-func (o *option) Count() int               { return o.count }
-func (o *option) IsFlag() bool             { return o.flag }
-func (o *option) String() string           { return o.value.String() }
-func (o *option) SetOptional() Option      { o.optional = true; return o }
+func (o *option) Seen() bool     { return o.count > 0 }
+func (o *option) Count() int     { return o.count }
+func (o *option) IsFlag() bool   { return o.flag }
+func (o *option) String() string { return o.value.String() }
+func (o *option) SetOptional() Option {
+	o.optional = true
+	if o.positional && o.set != nil {
+		o.set.parameters = o.set.positionalUsage()
+	}
+	return o
+}
 func (o *option) SetFlag() Option          { o.flag = true; return o }
 func (o *option) Mandatory() Option        { o.mandatory = true; return o }
 func (o *option) SetGroup(g string) Option { o.group = g; return o }
 
+func (o *option) SetCompleter(c Completer) Option { o.completer = c; return o }
+func (o *option) SetSection(name string) Option   { o.section = name; return o }
+
+func (o *option) SetVariadic() Option {
+	o.variadic = true
+	if o.positional && o.set != nil {
+		o.set.parameters = o.set.positionalUsage()
+	}
+	return o
+}
+
 func (o *option) Value() Value {
 	if o == nil {
 		return nil
@@ -152,10 +306,31 @@ func (o *option) Value() Value {
 }
 
 func (o *option) Name() string {
+	if o.positional {
+		return o.name
+	}
+	if o.alias != "" {
+		return o.alias
+	}
 	if !o.isLong && o.short != 0 {
 		return "-" + string(o.short)
 	}
-	return "--" + o.long
+	if o.isLong && o.negated != "" {
+		return o.longDash() + o.negated
+	}
+	return o.longDash() + o.long
+}
+
+// longDash returns the dash the long name of o was last used with, or, if it
+// has not been used, the dash of long names in the set of o.
+func (o *option) longDash() string {
+	switch {
+	case o.isLong && o.dash != "":
+		return o.dash
+	case o.set != nil:
+		return o.set.longDash()
+	}
+	return "--"
 }
 
 func (o *option) ShortName() string {
@@ -173,10 +348,20 @@ func (o *option) Help() string {
 	return o.help
 }
 
+func (o *option) Occurrences() []Occurrence {
+	return o.occurrences
+}
+
 // Reset rests an option so that it appears it has not yet been seen.
 func (o *option) Reset() {
 	o.isLong = false
+	o.negated = ""
+	o.alias = ""
+	o.dash = ""
 	o.count = 0
+	o.occurrences = nil
+	o.envSet = ""
+	o.config = ""
 	o.value.Set(o.defval, o)
 }
 
@@ -215,18 +400,37 @@ func (s *Set) AddOption(o Option) {
 		}
 	}
 	if opt.short != 0 {
-		if oo, ok := s.shortOptions[opt.short]; ok {
-			fmt.Fprintf(stderr, "%s: -%c already declared at %s\n", opt.where, opt.short, oo.where)
-			exit(1)
-		}
-		s.shortOptions[opt.short] = opt
+		s.addShort(opt, opt.short)
 	}
 	if opt.long != "" {
-		if oo, ok := s.longOptions[opt.long]; ok {
-			fmt.Fprintf(stderr, "%s: --%s already declared at %s\n", opt.where, opt.long, oo.where)
-			exit(1)
-		}
-		s.longOptions[opt.long] = opt
+		s.addLong(opt, opt.long)
+	}
+	for _, c := range opt.shortAliases {
+		s.addShort(opt, c)
+	}
+	for _, name := range opt.longAliases {
+		s.addLong(opt, name)
+	}
+	if opt.set == nil {
+		opt.set = s
 	}
 	s.options = append(s.options, opt)
 }
+
+// addShort adds c as a short name of opt in s.
+func (s *Set) addShort(opt *option, c rune) {
+	if oo, ok := s.shortOptions[c]; ok {
+		fmt.Fprintf(stderr, "%s: -%c already declared at %s\n", opt.where, c, oo.where)
+		exit(1)
+	}
+	s.shortOptions[c] = opt
+}
+
+// addLong adds name as a long name of opt in s.
+func (s *Set) addLong(opt *option, name string) {
+	if oo, ok := s.longOptions[name]; ok {
+		fmt.Fprintf(stderr, "%s: --%s already declared at %s\n", opt.where, name, oo.where)
+		exit(1)
+	}
+	s.longOptions[name] = opt
+}
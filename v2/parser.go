@@ -0,0 +1,415 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// An EventKind is the kind of an Event returned by a Parser.
+type EventKind int
+
+const (
+	OptionEvent   = EventKind(iota) // an option was parsed
+	OperandEvent                    // a non-option argument was found
+	DashDashEvent                   // "--" was found
+	ErrorEvent                      // an error was encountered
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case OptionEvent:
+		return "option"
+	case OperandEvent:
+		return "operand"
+	case DashDashEvent:
+		return "dash-dash"
+	case ErrorEvent:
+		return "error"
+	}
+	return "unknown event"
+}
+
+// An Event is a single item parsed by a Parser.
+type Event struct {
+	Kind EventKind
+
+	// Option is the option parsed by an OptionEvent.  It is also set by an
+	// ErrorEvent if the error is associated with a known option.
+	Option Option
+
+	// Name is the name of the option as it was used, such as "-v" or
+	// "--verbose".
+	Name string
+
+	// Value is the value passed to the option, or the operand of an
+	// OperandEvent.
+	Value string
+
+	// Attached is true if the value was part of the same argument as the
+	// option, as in -ovalue or --option=value, rather than the following
+	// argument.
+	Attached bool
+
+	// Long is true if the option was specified by its long name.
+	Long bool
+
+	// Arg is the raw argument the event was found in.
+	Arg string
+
+	// Index is the index of Arg in the arguments passed to Parser.
+	Index int
+
+	// Err is the error of an ErrorEvent.  It is normally an *Error.
+	Err error
+}
+
+// A Parser parses arguments using the options of a Set, one event at a time.
+// Getopt is implemented using a Parser so the two always parse arguments the
+// same way.  As with Getopt, each option is set as it is parsed.  A Parser is
+// typically used as:
+//
+//	p := s.Parser(args)
+//	for p.Next() {
+//		ev := p.Event()
+//		switch ev.Kind {
+//		case getopt.OptionEvent:
+//			...
+//		case getopt.OperandEvent:
+//			...
+//		case getopt.ErrorEvent:
+//			return ev.Err
+//		}
+//	}
+//
+// Once the options have been processed, all remaining arguments are returned
+// as OperandEvents.  If the Set is not permuting (see SetPermute) the options
+// end at the first non-option argument.
+type Parser struct {
+	s       *Set
+	args    []string // arguments to parse, after response files
+	index   []int    // index of each of args in the original arguments
+	next    int      // index into args of the next argument to parse
+	start   int      // index into args of the argument of the current event
+	cluster string   // remaining short options of the current argument
+	permute bool     // true if permuting
+	options bool     // true while options are still being processed
+	done    bool     // true when there are no more events
+	err     *Error   // error to return as the first event
+	dryRun  bool     // true if options are not set, see complete
+	collect bool     // true if parsing continues after errors
+	state   State
+	event   Event
+}
+
+// Parser returns a Parser for args using the options of s.  The first element
+// of args is used to assign the program for s if it is not yet set.  If
+// response files are enabled for s they are expanded before any options are
+// parsed.
+func (s *Set) Parser(args []string) *Parser {
+	p := &Parser{
+		s:       s,
+		permute: s.permuting(),
+		options: true,
+		collect: s.collectErrors,
+		state:   InProgress,
+	}
+	if len(args) == 0 {
+		p.done = true
+		return p
+	}
+	if s.program == "" {
+		s.program = path.Base(args[0])
+	}
+	p.args = args[1:]
+	if s.responseFiles {
+		expanded, index, err := expandResponseFiles(p.args, 1)
+		if err == nil {
+			p.args, p.index = expanded, index
+		} else {
+			p.err = err
+			p.start = err.Index - 1
+		}
+	}
+	if p.index == nil {
+		p.index = make([]int, len(p.args))
+		for x := range p.index {
+			p.index[x] = x + 1
+		}
+	}
+	return p
+}
+
+// Event returns the most recent event returned by Next.
+func (p *Parser) Event() Event { return p.event }
+
+// State returns the state of the parser.  It is InProgress while options are
+// being parsed.  Once the options have been processed it is the reason they
+// ended, such as EndOfOptions, Dash, DashDash, EndOfArguments or Failure.
+func (p *Parser) State() State { return p.state }
+
+// rest returns the arguments starting with the argument of the current event.
+func (p *Parser) rest() []string {
+	return p.args[p.start:]
+}
+
+// Next parses the next event, which is then available from Event.  Next
+// returns false when there are no more events.  No events follow an
+// ErrorEvent unless the Set is collecting errors (see SetCollectErrors).
+func (p *Parser) Next() bool {
+	if p.done {
+		return false
+	}
+	if p.err != nil {
+		return p.fail(p.err, nil)
+	}
+	if p.cluster != "" {
+		return p.short()
+	}
+	if p.next >= len(p.args) {
+		p.done = true
+		if p.state == InProgress {
+			p.state = EndOfArguments
+		}
+		return false
+	}
+	p.start = p.next
+	arg := p.args[p.next]
+	p.next++
+
+	if !p.options {
+		return p.operand(arg)
+	}
+
+	// end of options?
+	if arg == "" || arg[0] != '-' {
+		if !p.permute {
+			p.endOptions(EndOfOptions)
+		}
+		return p.operand(arg)
+	}
+
+	if arg == "-" {
+		// In traditional getopt, if - is not registered as an
+		// option, a lone - is treated as if there were a -- in
+		// front of it.
+		if p.s.shortOptions['-'] == nil {
+			if !p.permute {
+				p.endOptions(Dash)
+			}
+			return p.operand(arg)
+		}
+		p.cluster = arg
+		return p.short()
+	}
+
+	// explicitly request end of options?
+	if arg == "--" {
+		p.endOptions(DashDash)
+		return p.emit(Event{Kind: DashDashEvent})
+	}
+
+	if long, dash := p.s.longName(arg); long != "" {
+		if p.long(long, dash) {
+			return true
+		}
+	}
+	p.cluster = arg[1:] // strip -
+	return p.short()
+}
+
+// endOptions ends the processing of options because of state.
+func (p *Parser) endOptions(state State) {
+	p.options = false
+	p.state = state
+}
+
+// emit sets the current event to ev, filling in the argument it was found in.
+// The use of an option is recorded as an Occurrence.
+func (p *Parser) emit(ev Event) bool {
+	ev.Arg = p.args[p.start]
+	ev.Index = p.index[p.start]
+	p.event = ev
+	if opt, ok := ev.Option.(*option); ok && ev.Kind == OptionEvent && !p.dryRun {
+		occ := Occurrence{
+			Option: opt,
+			Name:   ev.Name,
+			Value:  ev.Value,
+			Index:  ev.Index,
+			Long:   ev.Long,
+		}
+		opt.occurrences = append(opt.occurrences, occ)
+		p.s.occurrences = append(p.s.occurrences, occ)
+	}
+	return true
+}
+
+// operand returns an OperandEvent for arg.
+func (p *Parser) operand(arg string) bool {
+	return p.emit(Event{Kind: OperandEvent, Value: arg})
+}
+
+// fail returns an ErrorEvent for err, which is associated with opt if opt is
+// not nil, and ends parsing unless p is collecting errors.  The rest of the
+// current cluster of short options is skipped.
+func (p *Parser) fail(err *Error, opt *option) bool {
+	if !p.collect || err.ErrorCode == BadResponseFile {
+		p.done = true
+		p.state = Failure
+	}
+	p.err = nil
+	p.cluster = ""
+	ev := Event{Kind: ErrorEvent, Name: err.Name, Err: err}
+	if opt != nil {
+		ev.Option = opt
+	}
+	if p.start < len(p.args) {
+		ev.Arg = p.args[p.start]
+		ev.Index = p.index[p.start]
+	}
+	if err.Index == 0 {
+		err.Index = ev.Index
+	}
+	p.event = ev
+	return true
+}
+
+// skipValue skips the next argument when p is collecting errors if it may be
+// the value of an unknown option, that is, if it does not start with a dash.
+func (p *Parser) skipValue() {
+	if p.collect && p.next < len(p.args) && !strings.HasPrefix(p.args[p.next], "-") {
+		p.next++
+	}
+}
+
+// long parses the long option name (which may include =value) introduced by
+// dash.  It returns false if name should be parsed as short options instead.
+func (p *Parser) long(name, dash string) bool {
+	s := p.s
+	e := strings.IndexRune(name, '=')
+	var value string
+	if e > 0 {
+		value = name[e+1:]
+		name = name[:e]
+	}
+	opt, long, negated, err := s.lookupLong(name, dash)
+	if err != nil {
+		// In SingleDashLong mode, -abc is treated as short
+		// options if abc is not a long option.
+		if dash == "-" && s.singleDash == SingleDashLong && err.ErrorCode == UnknownOption {
+			return false
+		}
+		if e < 0 {
+			p.skipValue()
+		}
+		return p.fail(err, nil)
+	}
+	// A short name used with a single dash, as in -v, is a short option.
+	isLong := long != "" || dash != "-"
+	if !p.dryRun {
+		opt.isLong = isLong
+		opt.negated = negated
+		opt.alias = ""
+		opt.dash = dash
+		if negated == "" && long != "" && long != opt.long {
+			opt.alias = dash + long
+		}
+	}
+	used := opt.Name()
+	if fwd := p.forward(opt); fwd != opt {
+		opt = fwd
+		opt.isLong = true
+		opt.negated = ""
+		opt.alias = ""
+		opt.dash = dash
+	}
+	ev := Event{Kind: OptionEvent, Option: opt, Name: used, Long: isLong, Attached: e > 0}
+	if negated != "" {
+		if e > 0 {
+			return p.fail(extraArg(opt, value), opt)
+		}
+		if !p.dryRun {
+			opt.count++
+			if err := opt.negate(); err != nil {
+				return p.fail(setError(opt, "", err), opt)
+			}
+		}
+		return p.emit(ev)
+	}
+	// If we require an option and did not have an =
+	// then use the next argument as an option.
+	if !opt.flag && e < 0 && !opt.optional {
+		if p.next >= len(p.args) {
+			return p.fail(missingArg(opt), opt)
+		}
+		value = p.args[p.next]
+		p.next++
+	}
+	if err := p.set(opt, value); err != nil {
+		return p.fail(err, opt)
+	}
+	ev.Value = value
+	return p.emit(ev)
+}
+
+// short parses the next short option in the current cluster.
+func (p *Parser) short() bool {
+	c, size := utf8.DecodeRuneInString(p.cluster)
+	p.cluster = p.cluster[size:]
+	opt := p.s.shortOptions[c]
+	if opt == nil {
+		if p.cluster == "" {
+			p.skipValue()
+		}
+		return p.fail(p.s.suggest(unknownOption(c)), nil)
+	}
+	if !p.dryRun {
+		opt.isLong = false
+		opt.negated = ""
+		opt.alias = ""
+		if c != opt.short {
+			opt.alias = "-" + string(c)
+		}
+	}
+	used := opt.Name()
+	if fwd := p.forward(opt); fwd != opt {
+		opt = fwd
+		opt.isLong = opt.short == 0
+		opt.negated = ""
+		opt.alias = ""
+	}
+	ev := Event{Kind: OptionEvent, Option: opt, Name: used}
+	var value string
+	if !opt.flag {
+		value, p.cluster = p.cluster, ""
+		ev.Attached = value != ""
+		if value == "" && !opt.optional {
+			if p.next >= len(p.args) {
+				return p.fail(missingArg(opt), opt)
+			}
+			value = p.args[p.next]
+			p.next++
+		}
+	}
+	if err := p.set(opt, value); err != nil {
+		return p.fail(err, opt)
+	}
+	ev.Value = value
+	return p.emit(ev)
+}
+
+// set sets opt to value, unless p is a dry run.
+func (p *Parser) set(opt *option, value string) *Error {
+	if p.dryRun {
+		return nil
+	}
+	opt.count++
+	if err := opt.setValue(value); err != nil {
+		return setError(opt, value, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// eventString returns a compact description of ev for testing.
+func eventString(ev Event) string {
+	switch ev.Kind {
+	case OptionEvent:
+		s := fmt.Sprintf("%s=%s@%d", ev.Name, ev.Value, ev.Index)
+		if ev.Attached {
+			s += ",attached"
+		}
+		if ev.Long {
+			s += ",long"
+		}
+		return s
+	case ErrorEvent:
+		return fmt.Sprintf("error(%v)@%d", ev.Err, ev.Index)
+	}
+	return fmt.Sprintf("%v(%s)@%d", ev.Kind, ev.Value, ev.Index)
+}
+
+func TestParser(t *testing.T) {
+	for _, tt := range []struct {
+		where   string
+		in      []string
+		permute bool
+		events  string
+		state   State
+	}{
+		{
+			where:  loc(),
+			in:     []string{"test", "-ovalue", "-o", "value", "--opt=v", "--opt", "v"},
+			events: "-o=value@1,attached -o=value@2 --opt=v@4,attached,long --opt=v@5,long",
+			state:  EndOfArguments,
+		},
+		{
+			where:  loc(),
+			in:     []string{"test", "-fo", "x", "y", "-f"},
+			events: "-f=@1 -o=x@1 operand(y)@3 operand(-f)@4",
+			state:  EndOfOptions,
+		},
+		{
+			where:   loc(),
+			in:      []string{"test", "y", "-f", "--", "-f"},
+			permute: true,
+			events:  "operand(y)@1 -f=@2 dash-dash()@3 operand(-f)@4",
+			state:   DashDash,
+		},
+		{
+			where:  loc(),
+			in:     []string{"test", "-", "-f"},
+			events: "operand(-)@1 operand(-f)@2",
+			state:  Dash,
+		},
+		{
+			where:  loc(),
+			in:     []string{"test", "-f", "-x", "-f"},
+			events: "-f=@1 error(unknown option: -x)@2",
+			state:  Failure,
+		},
+		{
+			where:  loc(),
+			in:     []string{"test", "-f", "-o"},
+			events: "-f=@1 error(missing parameter for -o)@2",
+			state:  Failure,
+		},
+	} {
+		reset()
+		var f bool
+		var o string
+		Flag(&f, 'f')
+		FlagLong(&o, "opt", 'o')
+		SetPermute(tt.permute)
+
+		p := CommandLine.Parser(tt.in)
+		var events []string
+		for p.Next() {
+			events = append(events, eventString(p.Event()))
+		}
+		if got := strings.Join(events, " "); got != tt.events {
+			t.Errorf("%s: got events:\n%s\nwant:\n%s", tt.where, got, tt.events)
+		}
+		if got := p.State(); got != tt.state {
+			t.Errorf("%s: got state %v, want %v", tt.where, got, tt.state)
+		}
+	}
+}
+
+func TestParserResume(t *testing.T) {
+	reset()
+	var f bool
+	Flag(&f, 'f')
+	p := CommandLine.Parser([]string{"test", "-f", "a"})
+	if !p.Next() || p.Event().Kind != OptionEvent {
+		t.Fatalf("got %v, want an option", p.Event())
+	}
+	if !f {
+		t.Errorf("-f not set by Next")
+	}
+	if !p.Next() || p.Event().Kind != OperandEvent {
+		t.Fatalf("got %v, want an operand", p.Event())
+	}
+	if p.Next() {
+		t.Errorf("got unexpected event %v", p.Event())
+	}
+	if p.Next() {
+		t.Errorf("Next returned true after end")
+	}
+}
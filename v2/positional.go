@@ -0,0 +1,151 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Positional is shorthand for CommandLine.Positional.
+func Positional(v interface{}, name string, help ...string) Option {
+	return CommandLine.Positional(v, name, help...)
+}
+
+// Positional declares a positional parameter named name in s and returns it
+// as an Option.  v must be one of the types accepted by FlagLong.  The
+// positional parameters are assigned, in the order they are declared, from
+// the non-option arguments remaining after Getopt has parsed the options.
+// Args continues to return all the non-option arguments.
+//
+// Positional parameters are required unless SetOptional is called.  An
+// optional parameter is only assigned if there are more arguments than
+// required parameters.  The last positional parameter may be made variadic
+// with SetVariadic, in which case it is set from each of the remaining
+// arguments.  A variadic parameter normally is a pointer to a []string.
+// Unlike a list option, a []string parameter does not split its arguments
+// at commas.
+//
+// Getopt returns an Error with the ErrorCode MissingPositional or
+// TooManyPositionals if the number of arguments does not match the
+// declarations, or Invalid if an argument cannot be converted.
+//
+// Declaring a positional parameter sets the parameters displayed in the usage
+// line (see SetParameters) to describe the declared positional parameters,
+// for example "src [dst]" or "file ...".  Help, if provided, is the help
+// message of the parameter.
+func (s *Set) Positional(v interface{}, name string, help ...string) Option {
+	p, flag := toValue(v)
+	opt := &option{
+		name:       name,
+		value:      p,
+		defval:     p.String(),
+		flag:       flag,
+		positional: true,
+		set:        s,
+		where:      calledFrom(),
+	}
+	switch len(help) {
+	case 0:
+	case 1:
+		opt.help = help[0]
+	default:
+		panic("Too many strings for Positional help")
+	}
+	if n := len(s.positionals); n > 0 && s.positionals[n-1].variadic {
+		fmt.Fprintf(stderr, "%s: positional parameter %s declared after variadic parameter %s\n", opt.where, name, s.positionals[n-1].name)
+		exit(1)
+	}
+	s.positionals = append(s.positionals, opt)
+	s.parameters = s.positionalUsage()
+	return opt
+}
+
+// positionalUsage returns the usage of the positional parameters in s.
+func (s *Set) positionalUsage() string {
+	var parts []string
+	for _, opt := range s.positionals {
+		n := opt.name
+		if opt.variadic {
+			n += " ..."
+		}
+		if opt.optional {
+			n = "[" + n + "]"
+		}
+		parts = append(parts, n)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Positionals returns the positional parameters declared in s, in the order
+// they were declared.
+func (s *Set) Positionals() []Option {
+	var opts []Option
+	for _, opt := range s.positionals {
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+// setPositionals sets the positional parameters in s from args.  For each
+// argument, index contains its index in the arguments passed to Getopt.
+func (s *Set) setPositionals(args []string, index []int) error {
+	required := 0
+	for _, opt := range s.positionals {
+		opt.Reset()
+		// Reset sets a list with no default to [""].
+		if p, ok := genericValue(opt.value).(*[]string); ok && opt.defval == "" {
+			*p = nil
+		}
+		if !opt.optional {
+			required++
+		}
+	}
+	for _, opt := range s.positionals {
+		switch {
+		case !opt.optional:
+			required--
+		case len(args) <= required:
+			continue
+		}
+		if len(args) == 0 {
+			return missingPositional(opt)
+		}
+		n := 1
+		if opt.variadic {
+			n = len(args)
+		}
+		for x, arg := range args[:n] {
+			opt.count++
+			if err := opt.setPositional(arg); err != nil {
+				e := setError(opt, arg, err)
+				e.Index = index[x]
+				return e
+			}
+		}
+		args, index = args[n:], index[n:]
+	}
+	if len(args) > 0 {
+		e := tooManyPositionals(args[0])
+		e.Index = index[0]
+		return e
+	}
+	return nil
+}
+
+// setPositional sets the positional parameter o from the argument arg.  The
+// argument is appended to a list as is, rather than split at commas, and the
+// first argument replaces the default value of the list.
+func (o *option) setPositional(arg string) error {
+	p, ok := genericValue(o.value).(*[]string)
+	if !ok {
+		return o.setValue(arg)
+	}
+	if o.count <= 1 {
+		*p = nil
+	}
+	*p = append(*p, arg)
+	return o.validate(arg)
+}
@@ -0,0 +1,160 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"testing"
+)
+
+var positionalTests = []struct {
+	where string
+	in    []string
+	src   string
+	n     int
+	dst   string
+	rest  []string
+	err   string
+	code  ErrorCode
+	index int
+}{
+	{
+		where: loc(),
+		in:    []string{"test", "a", "1"},
+		src:   "a",
+		n:     1,
+	},
+	{
+		where: loc(),
+		in:    []string{"test", "-v", "a", "2", "b"},
+		src:   "a",
+		n:     2,
+		dst:   "b",
+	},
+	{
+		where: loc(),
+		in:    []string{"test", "a", "3", "b", "c", "d"},
+		src:   "a",
+		n:     3,
+		dst:   "b",
+		rest:  []string{"c", "d"},
+	},
+	{
+		where: loc(),
+		in:    []string{"test", "a", "3", "b", "c,d.txt", "e"},
+		src:   "a",
+		n:     3,
+		dst:   "b",
+		rest:  []string{"c,d.txt", "e"},
+	},
+	{
+		where: loc(),
+		in:    []string{"test", "a"},
+		err:   "test: missing count",
+		code:  MissingPositional,
+	},
+	{
+		where: loc(),
+		in:    []string{"test", "a", "x"},
+		err:   "test: not a valid number: x",
+		code:  Invalid,
+		index: 2,
+	},
+	{
+		where: loc(),
+		in:    []string{"test", "-v", "a", "x"},
+		err:   "test: not a valid number: x",
+		code:  Invalid,
+		index: 3,
+	},
+}
+
+func TestPositional(t *testing.T) {
+	for _, tt := range positionalTests {
+		reset()
+		var v bool
+		var src, dst string
+		var n int
+		rest := []string{}
+		Flag(&v, 'v')
+		Positional(&src, "src")
+		Positional(&n, "count")
+		Positional(&dst, "dst").SetOptional()
+		Positional(&rest, "rest").SetOptional().SetVariadic()
+
+		parse(tt.in)
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.where, s)
+		}
+		if tt.err != "" {
+			e, ok := CommandLine.Getopt(tt.in, nil).(*Error)
+			switch {
+			case !ok:
+				t.Errorf("%s: did not get an *Error", tt.where)
+			case e.ErrorCode != tt.code || e.Index != tt.index:
+				t.Errorf("%s: got %v at %d, want %v at %d", tt.where, e.ErrorCode, e.Index, tt.code, tt.index)
+			}
+			continue
+		}
+		if src != tt.src || n != tt.n || dst != tt.dst {
+			t.Errorf("%s: got %q %d %q, want %q %d %q", tt.where, src, n, dst, tt.src, tt.n, tt.dst)
+		}
+		if badSlice(rest, tt.rest) {
+			t.Errorf("%s: got rest %q, want %q", tt.where, rest, tt.rest)
+		}
+		if badSlice(Args(), tt.in[len(tt.in)-NArgs():]) {
+			t.Errorf("%s: Args changed to %q", tt.where, Args())
+		}
+	}
+}
+
+func TestPositionalPermuted(t *testing.T) {
+	reset()
+	var v bool
+	var files []string
+	Flag(&v, 'v')
+	Positional(&files, "file").SetVariadic()
+	SetPermute(true)
+
+	err := CommandLine.Getopt([]string{"test", "a", "-v", "b"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; badSlice(files, want) {
+		t.Errorf("got %q, want %q", files, want)
+	}
+	err = CommandLine.Getopt([]string{"test", "-v"}, nil)
+	if e, ok := err.(*Error); !ok || e.ErrorCode != MissingPositional {
+		t.Errorf("got error %v, want MissingPositional", err)
+	}
+}
+
+func TestPositionalTooMany(t *testing.T) {
+	reset()
+	var src string
+	Positional(&src, "src")
+	err := CommandLine.Getopt([]string{"test", "a", "b"}, nil)
+	e, ok := err.(*Error)
+	if !ok || e.ErrorCode != TooManyPositionals || e.Index != 2 {
+		t.Errorf("got error %#v, want TooManyPositionals at 2", err)
+	}
+}
+
+func TestPositionalUsage(t *testing.T) {
+	reset()
+	var v bool
+	var src, dst string
+	var rest []string
+	Flag(&v, 'v')
+	SetProgram("test")
+	Positional(&src, "src")
+	Positional(&dst, "dst").SetOptional()
+	Positional(&rest, "rest").SetOptional().SetVariadic()
+	var buf bytes.Buffer
+	PrintUsage(&buf)
+	if got, want := buf.String(), "Usage: test [-v] src [dst] [rest ...]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// readFile allows tests to provide response files without the file system.
+var readFile = ioutil.ReadFile
+
+// SetResponseFiles sets whether response files are expanded in the command
+// line arguments.  See Set.SetResponseFiles for details.
+func SetResponseFiles(expand bool) {
+	CommandLine.SetResponseFiles(expand)
+}
+
+// SetResponseFiles sets whether Getopt expands response files.  When expand
+// is true, each argument of the form @path is replaced by the words contained
+// in the file path before any options are parsed.  This allows command lines
+// longer than the system permits, as is done by gcc and javac.
+//
+// Words in a response file are separated by white space.  White space may be
+// included in a word by quoting it with single or double quotes or by
+// preceding it with a backslash.  Within double quotes, a backslash escapes
+// the following character.  A # at the start of a word begins a comment that
+// continues to the end of the line.
+//
+// A word in a response file that starts with @ is itself a response file.
+// Relative paths are relative to the current directory.  A response file that
+// includes itself, directly or indirectly, is an error.  An argument or word
+// that starts with @@ is not expanded but has its leading @ removed, so @@x
+// is the literal argument @x.
+//
+// Errors in response files have the ErrorCode BadResponseFile and include the
+// file name and line number.  The Index of an Error always refers to the
+// original, unexpanded, arguments.
+func (s *Set) SetResponseFiles(expand bool) {
+	s.responseFiles = expand
+}
+
+// expandResponseFiles returns args with all response files expanded.  For
+// each returned argument, index contains the index of the argument in args
+// that it came from, plus offset.
+func expandResponseFiles(args []string, offset int) (expanded []string, index []int, err *Error) {
+	for x, arg := range args {
+		words, err := expandWord(arg, nil, "", 0)
+		if err != nil {
+			err.Index = x + offset
+			return nil, nil, err
+		}
+		expanded = append(expanded, words...)
+		for range words {
+			index = append(index, x+offset)
+		}
+	}
+	return expanded, index, nil
+}
+
+// expandWord returns the expansion of word, which was found in the file
+// named from at line.  Stack contains the response files currently being
+// expanded and is used to detect cycles.
+func expandWord(word string, stack []string, from string, line int) ([]string, *Error) {
+	switch {
+	case strings.HasPrefix(word, "@@"):
+		return []string{word[1:]}, nil
+	case !strings.HasPrefix(word, "@") || word == "@":
+		return []string{word}, nil
+	}
+	path := word[1:]
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	for x, p := range stack {
+		if p == abs {
+			cycle := append(append([]string{}, stack[x:]...), abs)
+			return nil, responseError(word, from, line, fmt.Errorf("response file cycle: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+	data, err := readFile(path)
+	if err != nil {
+		return nil, responseError(word, from, line, err)
+	}
+	stack = append(stack, abs)
+	var expanded []string
+	for _, w := range splitResponseFile(string(data)) {
+		if w.err != nil {
+			return nil, responseError(word, path, w.line, w.err)
+		}
+		words, err := expandWord(w.word, stack, path, w.line)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, words...)
+	}
+	return expanded, nil
+}
+
+// A responseWord is a word found in a response file on line.  If err is not
+// nil then the word could not be parsed.
+type responseWord struct {
+	word string
+	line int
+	err  error
+}
+
+// splitResponseFile splits data into words.  If a word cannot be parsed then
+// the last word returned has its err set.
+func splitResponseFile(data string) []responseWord {
+	var words []responseWord
+	var word []rune
+	line := 1
+	inWord := false // true if we are in a word, even an empty one
+	var quote rune  // the quote character we are in, or 0
+	start := 0      // the line where the current word started
+	escaped := false
+
+	runes := []rune(data)
+	for x := 0; x < len(runes); x++ {
+		c := runes[x]
+		if c == '\n' {
+			line++
+		}
+		switch {
+		case escaped:
+			escaped = false
+			// A backslash-newline outside of quotes is
+			// a line continuation.
+			if c != '\n' || quote != 0 {
+				word = append(word, c)
+			}
+			continue
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word = append(word, c)
+			}
+			continue
+		case quote == '"':
+			switch c {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word = append(word, c)
+			}
+			continue
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			if inWord {
+				words = append(words, responseWord{word: string(word), line: start})
+				word, inWord = word[:0], false
+			}
+			continue
+		case '#':
+			if !inWord {
+				for x < len(runes)-1 && runes[x+1] != '\n' {
+					x++
+				}
+				continue
+			}
+		}
+		if !inWord {
+			inWord, start = true, line
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '\\':
+			escaped = true
+		default:
+			word = append(word, c)
+		}
+	}
+	switch {
+	case quote != 0:
+		words = append(words, responseWord{line: start, err: fmt.Errorf("unterminated %c quote", quote)})
+	case escaped:
+		words = append(words, responseWord{line: start, err: errors.New("backslash at end of file")})
+	case inWord:
+		words = append(words, responseWord{word: string(word), line: start})
+	}
+	return words
+}
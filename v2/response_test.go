@@ -0,0 +1,146 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+var splitTests = []struct {
+	in  string
+	out []string
+	err string
+}{
+	{"", nil, ""},
+	{"a b\tc\n d", []string{"a", "b", "c", "d"}, ""},
+	{`'a b' "c d"`, []string{"a b", "c d"}, ""},
+	{`a\ b "c\"d" 'e\f'`, []string{"a b", `c"d`, `e\f`}, ""},
+	{"'' x", []string{"", "x"}, ""},
+	{"# comment\na # comment\nb#c", []string{"a", "b#c"}, ""},
+	{"a\\\nb", []string{"ab"}, ""},
+	{"a\n'b", []string{"a"}, "2: unterminated ' quote"},
+	{"a\\", nil, "1: backslash at end of file"},
+}
+
+func TestSplitResponseFile(t *testing.T) {
+	for x, tt := range splitTests {
+		var out []string
+		var err string
+		for _, w := range splitResponseFile(tt.in) {
+			if w.err != nil {
+				err = fmt.Sprintf("%d: %v", w.line, w.err)
+				break
+			}
+			out = append(out, w.word)
+		}
+		if badSlice(out, tt.out) {
+			t.Errorf("#%d: got %q, want %q", x, out, tt.out)
+		}
+		if err != tt.err {
+			t.Errorf("#%d: got error %q, want %q", x, err, tt.err)
+		}
+	}
+}
+
+var responseFiles = map[string]string{
+	"args":   "-a 'x y'\n@nested\n@@at",
+	"nested": "# options\n-b\nfile\n",
+	"loop1":  "-a\n@loop2\n",
+	"loop2":  "\n\n@loop1",
+	"bad":    "-a\n\n'unterminated",
+	"opts":   "-a x\n-b",
+}
+
+func TestResponseFiles(t *testing.T) {
+	defer func(rf func(string) ([]byte, error)) { readFile = rf }(readFile)
+	readFile = func(path string) ([]byte, error) {
+		if data, ok := responseFiles[path]; ok {
+			return []byte(data), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+
+	for _, tt := range []struct {
+		where string
+		in    []string
+		a     string
+		b     bool
+		args  []string
+		err   string
+		index int
+		file  string
+		line  int
+	}{
+		{
+			where: loc(),
+			in:    []string{"test", "@args", "arg"},
+			a:     "x y",
+			b:     true,
+			args:  []string{"file", "@at", "arg"},
+		},
+		{
+			where: loc(),
+			in:    []string{"test", "@@args"},
+			args:  []string{"@args"},
+		},
+		{
+			where: loc(),
+			in:    []string{"test", "-b", "@missing"},
+			err:   "test: open missing: file does not exist",
+			index: 2,
+		},
+		{
+			where: loc(),
+			in:    []string{"test", "-b", "@bad"},
+			err:   "test: bad:3: unterminated ' quote",
+			index: 2,
+			file:  "bad",
+			line:  3,
+		},
+		{
+			where: loc(),
+			in:    []string{"test", "@loop1"},
+			err:   "test: loop2:3: response file cycle: ",
+			index: 1,
+			file:  "loop2",
+			line:  3,
+		},
+		{
+			where: loc(),
+			in:    []string{"test", "@opts", "-c"},
+			err:   "test: unknown option: -c",
+			index: 2,
+		},
+	} {
+		reset()
+		var a string
+		var b bool
+		Flag(&a, 'a')
+		Flag(&b, 'b')
+		SetResponseFiles(true)
+		parse(tt.in)
+		if s := checkError(tt.err); s != "" {
+			t.Errorf("%s: %s", tt.where, s)
+		}
+		if tt.err != "" {
+			e, ok := CommandLine.Getopt(tt.in, nil).(*Error)
+			switch {
+			case !ok:
+				t.Errorf("%s: did not get an *Error", tt.where)
+			case e.Index != tt.index || e.File != tt.file || e.Line != tt.line:
+				t.Errorf("%s: got %d %s:%d, want %d %s:%d", tt.where, e.Index, e.File, e.Line, tt.index, tt.file, tt.line)
+			}
+			continue
+		}
+		if a != tt.a || b != tt.b {
+			t.Errorf("%s: got %q %v, want %q %v", tt.where, a, b, tt.a, tt.b)
+		}
+		if badSlice(Args(), tt.args) {
+			t.Errorf("%s: got args %q, want %q", tt.where, Args(), tt.args)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import "sort"
+
+// A section is a block of options displayed together under a heading.
+type section struct {
+	name  string // the heading of the section, such as "Network options"
+	intro string // text displayed below the heading
+}
+
+// AddSection calls AddSection on the command line options.
+func AddSection(name, intro string) {
+	CommandLine.AddSection(name, intro)
+}
+
+// AddSection adds the display section name, with the introductory text intro,
+// to s.  Options are placed in a section with Option.SetSection.  Sections are
+// unrelated to the mutually exclusive groups of SetGroup.
+//
+// PrintOptions first prints the options that are not in a section and then
+// each section, in the order they were added, under the heading "name:"
+// followed by intro, if not empty.  Sections that are used but not added are
+// printed last, sorted by name.  The options within each section are sorted
+// as usual.  Adding a section again replaces its intro.
+func (s *Set) AddSection(name, intro string) {
+	for _, sec := range s.sections {
+		if sec.name == name {
+			sec.intro = intro
+			return
+		}
+	}
+	s.sections = append(s.sections, &section{name: name, intro: intro})
+}
+
+// SetCollapseSections calls SetCollapseSections on the command line options.
+func SetCollapseSections(collapse bool) {
+	CommandLine.SetCollapseSections(collapse)
+}
+
+// SetCollapseSections sets whether UsageLine lists the options in each
+// section of s or only the name of the section, in lower case, such as
+// "[network options]".
+func (s *Set) SetCollapseSections(collapse bool) {
+	s.collapseSections = collapse
+}
+
+// displaySections returns the sections of s that have options to display, in
+// the order they are displayed.
+func (s *Set) displaySections() []*section {
+	used := map[string]bool{}
+	for _, opt := range s.options {
+		if opt.section != "" && !s.hides(opt) {
+			used[opt.section] = true
+		}
+	}
+	var sections []*section
+	for _, sec := range s.sections {
+		if used[sec.name] {
+			sections = append(sections, sec)
+			delete(used, sec.name)
+		}
+	}
+	var names []string
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sections = append(sections, &section{name: name})
+	}
+	return sections
+}
+
+// collapsed returns true if opt is only listed by its section in the usage
+// line of s.
+func (s *Set) collapsed(opt *option) bool {
+	return s.collapseSections && opt.section != ""
+}
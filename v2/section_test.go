@@ -0,0 +1,156 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	HelpColumn = 20
+	reset()
+	SetProgram("prog")
+	var (
+		host, port, output, format string
+		debug                      bool
+	)
+	AddSection("Output options", "")
+	AddSection("Network options", "Options for connecting to the server.")
+	BoolLong("verbose", 'v', "be verbose")
+	FlagLong(&host, "host", 'H', "server host").SetSection("Network options")
+	FlagLong(&port, "port", 'p', "server port").SetSection("Network options")
+	FlagLong(&output, "output", 'o', "output file").SetSection("Output options")
+	FlagLong(&format, "format", 0, "output format").SetSection("Output options")
+	FlagLong(&debug, "debug", 'd', "debug").SetSection("Debugging options")
+	var b bytes.Buffer
+	PrintUsage(&b)
+	want := `Usage: prog [-dv] [--format value] [-H value] [-o value] [-p value] [parameters ...]
+ -v, --verbose     be verbose
+
+Output options:
+     --format=value
+                   output format
+ -o, --output=value
+                   output file
+
+Network options:
+ Options for connecting to the server.
+ -H, --host=value  server host
+ -p, --port=value  server port
+
+Debugging options:
+ -d, --debug       debug
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	SetCollapseSections(true)
+	want = "[-v] [output options] [network options] [debugging options]"
+	if got := CommandLine.UsageLine(); got != want {
+		t.Errorf("got usage %q, want %q", got, want)
+	}
+
+	// Sections and groups are independent.
+	var alpha, beta bool
+	s := New()
+	s.SetProgram("prog")
+	s.FlagLong(&alpha, "alpha", 'a', "alpha").SetGroup("method").SetSection("Methods")
+	s.FlagLong(&beta, "beta", 'b', "beta").SetGroup("method")
+	if err := s.Getopt([]string{"prog", "-a", "-b"}, nil); err == nil {
+		t.Errorf("did not get mutual exclusion error")
+	}
+	b.Reset()
+	s.PrintOptions(&b)
+	want = ` -b, --beta   beta {method}
+
+Methods:
+ -a, --alpha  alpha {method}
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSectionsManPage(t *testing.T) {
+	reset()
+	SetProgram("prog")
+	var (
+		host, port, output, format string
+		debug                      bool
+	)
+	AddSection("Output options", "")
+	AddSection("Network options", "Options for connecting to the server.")
+	BoolLong("verbose", 'v', "be verbose")
+	FlagLong(&host, "host", 'H', "server host").SetSection("Network options")
+	FlagLong(&port, "port", 'p', "server port").SetSection("Network options")
+	FlagLong(&output, "output", 'o', "output file").SetSection("Output options")
+	FlagLong(&format, "format", 0, "output format").SetSection("Output options")
+	FlagLong(&debug, "debug", 'd', "debug").SetSection("Debugging options")
+	var b bytes.Buffer
+	if err := WriteManPage(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `.SH OPTIONS
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+be verbose
+.SS Output options
+.TP
+\fB\-\-format\fR=\fIvalue\fR
+output format
+.TP
+\fB\-o\fR, \fB\-\-output\fR=\fIvalue\fR
+output file
+.SS Network options
+Options for connecting to the server.
+.TP
+\fB\-H\fR, \fB\-\-host\fR=\fIvalue\fR
+server host
+.TP
+\fB\-p\fR, \fB\-\-port\fR=\fIvalue\fR
+server port
+.SS Debugging options
+.TP
+\fB\-d\fR, \fB\-\-debug\fR
+debug
+`
+	if got := b.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("got:\n%s\nwant suffix:\n%s", got, want)
+	}
+}
+
+func TestSectionsJSON(t *testing.T) {
+	reset()
+	SetProgram("prog")
+	var (
+		host, port, output, format string
+		debug                      bool
+	)
+	AddSection("Output options", "")
+	AddSection("Network options", "Options for connecting to the server.")
+	BoolLong("verbose", 'v', "be verbose")
+	FlagLong(&host, "host", 'H', "server host").SetSection("Network options")
+	FlagLong(&port, "port", 'p', "server port").SetSection("Network options")
+	FlagLong(&output, "output", 'o', "output file").SetSection("Output options")
+	FlagLong(&format, "format", 0, "output format").SetSection("Output options")
+	FlagLong(&debug, "debug", 'd', "debug").SetSection("Debugging options")
+	var b bytes.Buffer
+	if err := CommandLine.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ReadJSON(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want bytes.Buffer
+	s.PrintUsage(&got)
+	PrintUsage(&want)
+	if got.String() != want.String() {
+		t.Errorf("got:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
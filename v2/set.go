@@ -11,6 +11,16 @@ import (
 	"sync"
 )
 
+// A SingleDashMode determines how a Set treats an argument that starts with a
+// single dash.
+type SingleDashMode int
+
+const (
+	SingleDashShort  = SingleDashMode(iota) // -abc is -a -b -c (the default)
+	SingleDashLong                          // -abc is --abc, if abc is a long option, else -a -b -c
+	SingleDashStrict                        // -abc is always --abc
+)
+
 // A State is why the Getopt returned.
 type State int
 
@@ -18,8 +28,8 @@ const (
 	InProgress     = State(iota) // Getopt is still running
 	Dash                         // Returned on "-"
 	DashDash                     // Returned on "--"
-	EndOfOptions                 // End of options reached
-	EndOfArguments               // No more arguments
+	EndOfOptions                 // End of options reached (non-option seen)
+	EndOfArguments               // No more arguments (all arguments parsed)
 	Terminated                   // Terminated by callback function
 	Failure                      // Terminated due to error
 	Unknown                      // Indicates internal error
@@ -43,22 +53,119 @@ type Set struct {
 
 	usage func() // usage should print the programs usage and exit.
 
-	shortOptions map[rune]*option
-	longOptions  map[string]*option
-	options      optionList
+	// permute causes Getopt to continue parsing options after
+	// encountering a non-option argument (see SetPermute).
+	permute bool
+
+	// posixlyCorrect disables permute, as does the POSIXLY_CORRECT
+	// environment variable (see SetPosixlyCorrect).
+	posixlyCorrect bool
+
+	// abbreviations allows long options to be abbreviated to any
+	// unique prefix (see SetAbbreviations).
+	abbreviations bool
+
+	// negation is used to negate flags and lists that have no negation
+	// of their own (see SetNegatable).
+	negation *negation
+
+	// singleDash is how arguments starting with a single dash are
+	// processed (see SetSingleDash).
+	singleDash SingleDashMode
+
+	// responseFiles causes @file arguments to be expanded (see
+	// SetResponseFiles).
+	responseFiles bool
+
+	// positionals are the declared positional parameters (see
+	// Positional).
+	positionals []*option
+
+	// envPrefix is the prefix used to name the environment variable
+	// bound to each option (see SetEnvPrefix).
+	envPrefix string
+
+	// occurrences are the uses of all options in s, in order.
+	occurrences []Occurrence
+
+	// dynamicCompletion causes Getopt to answer completion requests
+	// (see SetDynamicCompletion).
+	dynamicCompletion bool
+
+	// operandCompleter completes operands that are not positional
+	// parameters (see SetOperandCompleter).
+	operandCompleter Completer
+
+	// sections are the display sections of options, in the order they
+	// are displayed (see AddSection).
+	sections []*section
+
+	// collapseSections causes UsageLine to list sections rather than
+	// the options in them (see SetCollapseSections).
+	collapseSections bool
+
+	// showHidden causes hidden options to be displayed (see
+	// PrintUsageAll).
+	showHidden bool
+
+	// showAliases causes PrintOptions to display the aliases of options
+	// (see SetShowAliases).
+	showAliases bool
+
+	// output is where usage, errors and warnings are written, if not
+	// standard error (see SetOutput).
+	output io.Writer
+
+	// deprecationHook is called when a deprecated option is first used
+	// (see SetDeprecationHook).
+	deprecationHook func(Option, string)
+
+	// suggestDistance is the maximum edit distance of the options
+	// suggested for an unknown option (see SetSuggestDistance).
+	suggestDistance int
+
+	// collectErrors causes Getopt to continue parsing after errors (see
+	// SetCollectErrors).
+	collectErrors bool
+
+	// constraints are the constraints on groups of options (see
+	// AllOrNone, AtLeastOne and AtMost).
+	constraints []*constraint
+
+	// validators are called after the options have been checked (see
+	// AddValidator).
+	validators []func(*Set) error
+
+	// showRanges causes PrintOptions to display the allowed range of
+	// options (see SetShowRanges).
+	showRanges bool
+
+	// skipChecks causes Getopt not to check the options after parsing.
+	// Command sets it while parsing the options of a command that may
+	// dispatch to a subcommand, and checks the options itself.
+	skipChecks bool
+
+	shortOptions   map[rune]*option
+	longOptions    map[string]*option
+	options        optionList
 	requiredGroups []string
 }
 
+// defaultParameters is the default parameters string of a Set.
+const defaultParameters = "[parameters ...]"
+
 // New returns a newly created option set.
 func New() *Set {
 	s := &Set{
 		shortOptions: make(map[rune]*option),
 		longOptions:  make(map[string]*option),
-		parameters:   "[parameters ...]",
+		parameters:   defaultParameters,
+
+		suggestDistance: defaultSuggestDistance,
 	}
 
 	s.usage = func() {
-		s.PrintUsage(stderr)
+		s.PrintUsage(s.writer())
 	}
 	return s
 }
@@ -143,7 +250,8 @@ func SetParameters(parameters string) {
 }
 
 // SetParameters sets the parameters string for printing the s's usage.
-// It defaults to "[parameters ...]"
+// It defaults to "[parameters ...]", or if positional parameters have been
+// declared, a description of the positional parameters.
 func (s *Set) SetParameters(parameters string) {
 	s.parameters = parameters
 }
@@ -163,6 +271,91 @@ func (s *Set) SetProgram(program string) {
 	s.program = program
 }
 
+// SetPermute sets the permute mode of the command line options.  See
+// Set.SetPermute for details.
+func SetPermute(permute bool) {
+	CommandLine.SetPermute(permute)
+}
+
+// SetPermute sets the permute mode of s.  When permute is true, Getopt does
+// not stop at the first non-option argument but continues parsing, collecting
+// the non-option arguments, in order, into Args.  This is the default
+// behavior of GNU getopt_long.  Parsing still stops at "--", in which case the
+// arguments following "--" are appended to Args.  A lone "-" that is not
+// declared as an option is treated as a non-option argument.
+//
+// Permuting is disabled if s has been set to be posixly correct (see
+// SetPosixlyCorrect) or if the POSIXLY_CORRECT environment variable is set.
+func (s *Set) SetPermute(permute bool) {
+	s.permute = permute
+}
+
+// SetPosixlyCorrect sets the posixly correct mode of the command line
+// options.  See Set.SetPosixlyCorrect for details.
+func SetPosixlyCorrect(strict bool) {
+	CommandLine.SetPosixlyCorrect(strict)
+}
+
+// SetPosixlyCorrect, when strict is true, causes s to always stop parsing at
+// the first non-option argument, even if permuting is enabled with
+// SetPermute.  This has the same effect as setting the POSIXLY_CORRECT
+// environment variable.
+func (s *Set) SetPosixlyCorrect(strict bool) {
+	s.posixlyCorrect = strict
+}
+
+// permuting returns true if s should permute its arguments while parsing.
+func (s *Set) permuting() bool {
+	if !s.permute || s.posixlyCorrect {
+		return false
+	}
+	_, ok := os.LookupEnv("POSIXLY_CORRECT")
+	return !ok
+}
+
+// SetAbbreviations sets whether long command line options may be
+// abbreviated.  See Set.SetAbbreviations for details.
+func SetAbbreviations(allow bool) {
+	CommandLine.SetAbbreviations(allow)
+}
+
+// SetAbbreviations sets whether long options in s may be abbreviated to any
+// unique prefix of their name, as is done by GNU getopt_long.  For example,
+// --verb matches --verbose as long as no other long option starts with verb.
+// An exact match always takes precedence over a prefix.  A prefix that
+// matches more than one option results in an error with the ErrorCode
+// AmbiguousOption.
+func (s *Set) SetAbbreviations(allow bool) {
+	s.abbreviations = allow
+}
+
+// SetSingleDash sets the single dash mode of the command line options.  See
+// Set.SetSingleDash for details.
+func SetSingleDash(mode SingleDashMode) {
+	CommandLine.SetSingleDash(mode)
+}
+
+// SetSingleDash sets how s treats an argument that starts with a single dash,
+// such as -name.  By default (SingleDashShort), -name is the cluster of short
+// options -n -a -m -e.  With SingleDashLong, -name is the long option --name
+// if name is a long option, otherwise it is processed as short options.  With
+// SingleDashStrict, -name is always the long option --name and short options
+// cannot be clustered.  This is the style used by the Go flag package and
+// find(1).  Long options may still be introduced with "--" in all modes.  In
+// the SingleDashLong and SingleDashStrict modes, the usage displays long
+// options with a single dash.
+func (s *Set) SetSingleDash(mode SingleDashMode) {
+	s.singleDash = mode
+}
+
+// longDash returns the dash used to display long options in s.
+func (s *Set) longDash() string {
+	if s.singleDash != SingleDashShort {
+		return "-"
+	}
+	return "--"
+}
+
 // Program returns the program name associated with Set s.
 func (s *Set) Program() string { return s.program }
 
@@ -279,6 +472,18 @@ func (s *Set) VisitAll(fn func(Option)) {
 	}
 }
 
+// Occurrences returns each use of a command line option, in order.
+func Occurrences() []Occurrence {
+	return CommandLine.Occurrences()
+}
+
+// Occurrences returns each use of an option in s, in the order they were
+// parsed, since s was last reset.  Use Option.Occurrences for the uses of a
+// single option.
+func (s *Set) Occurrences() []Occurrence {
+	return s.occurrences
+}
+
 // Reset resets all the command line options to the initial state so it
 // appears none of them have been seen.
 func Reset() {
@@ -288,9 +493,13 @@ func Reset() {
 // Reset resets all the options in s to the initial state so it
 // appears none of them have been seen.
 func (s *Set) Reset() {
+	s.occurrences = nil
 	for _, opt := range s.options {
 		opt.Reset()
 	}
+	for _, opt := range s.positionals {
+		opt.Reset()
+	}
 }
 
 // RequiredGroup marks the group set with Option.SetGroup as required.  At least
@@ -0,0 +1,122 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultSuggestDistance is the maximum edit distance of the names suggested
+// for an unknown option, unless changed with SetSuggestDistance.
+const defaultSuggestDistance = 2
+
+// SetSuggestDistance calls SetSuggestDistance on the command line options.
+func SetSuggestDistance(distance int) {
+	CommandLine.SetSuggestDistance(distance)
+}
+
+// SetSuggestDistance sets the maximum edit distance between an unknown option
+// and the option names suggested in its Error (see Error.Suggestions).  Each
+// insertion, deletion, substitution or transposition of adjacent characters
+// counts as 1.  The default is 2.  A distance of 0 disables suggestions.  A
+// short name is only suggested for a short option of a different case.
+func (s *Set) SetSuggestDistance(distance int) {
+	s.suggestDistance = distance
+}
+
+// suggest sets the Suggestions of the unknown option err to the names of the
+// options in s that are close to the name of err, closest first.  Long names
+// are close if they are within the edit distance of s, short names if they
+// only differ in case.  Hidden options are never suggested.  Suggest returns
+// err.
+func (s *Set) suggest(err *Error) *Error {
+	if s.suggestDistance <= 0 {
+		return err
+	}
+	name := strings.TrimLeft(err.Name, "-")
+	runes := []rune(name)
+	max := s.suggestDistance
+	// A name can be turned into any name of the same length by
+	// replacing each of its characters, which is no suggestion at all.
+	if max >= len(runes) {
+		max = len(runes) - 1
+	}
+
+	distances := map[string]int{}
+	dash := s.longDash()
+	addLong := func(long string) {
+		if d := editDistance(runes, []rune(long)); d <= max {
+			distances[dash+long] = d
+		}
+	}
+	for _, opt := range s.options {
+		if opt.hidden || opt.positional {
+			continue
+		}
+		for _, l := range opt.longNames() {
+			addLong(l)
+			if n := s.negationFor(opt).negate(l); n != "" {
+				addLong(n)
+			}
+		}
+		for _, c := range opt.shortNames() {
+			if string(c) != name && strings.EqualFold(string(c), name) {
+				distances["-"+string(c)] = 1
+			}
+		}
+	}
+	if len(distances) == 0 {
+		return err
+	}
+	suggestions := make([]string, 0, len(distances))
+	for n := range distances {
+		suggestions = append(suggestions, n)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if distances[a] != distances[b] {
+			return distances[a] < distances[b]
+		}
+		return a < b
+	})
+	err.Suggestions = suggestions
+	return err
+}
+
+// editDistance returns the optimal string alignment distance between a and b,
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent runes needed to turn a into b.
+func editDistance(a, b []rune) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			best := d[i-1][j-1] + cost
+			if d[i-1][j]+1 < best {
+				best = d[i-1][j] + 1
+			}
+			if d[i][j-1]+1 < best {
+				best = d[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < best {
+				best = d[i-2][j-2] + 1
+			}
+			d[i][j] = best
+		}
+	}
+	return d[len(a)][len(b)]
+}
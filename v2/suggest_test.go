@@ -0,0 +1,129 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		a, b  string
+		want  int
+	}{
+		{loc(), "", "", 0},
+		{loc(), "abc", "", 3},
+		{loc(), "", "abc", 3},
+		{loc(), "verbose", "verbose", 0},
+		{loc(), "verbsoe", "verbose", 1},
+		{loc(), "verbos", "verbose", 1},
+		{loc(), "verrbose", "verbose", 1},
+		{loc(), "vrebsoe", "verbose", 2},
+		{loc(), "kitten", "sitting", 3},
+		{loc(), "héllo", "hello", 1},
+	} {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("%s: editDistance(%q, %q) got %d, want %d", tt.where, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	for _, tt := range []struct {
+		where    string
+		args     []string
+		distance int
+		want     []string
+	}{
+		{loc(), []string{"prog", "--verbsoe"}, 2, []string{"--verbose"}},
+		{loc(), []string{"prog", "--versoin"}, 2, []string{"--version"}},
+		{loc(), []string{"prog", "--verison"}, 2, []string{"--version"}},
+		{loc(), []string{"prog", "--verbsoe"}, 0, nil},
+		{loc(), []string{"prog", "--vers"}, 3, []string{"--verbose", "--version"}},
+		{loc(), []string{"prog", "--colur"}, 2, []string{"--color", "--colour"}},
+		{loc(), []string{"prog", "--no-colr"}, 2, []string{"--no-color", "--no-colour"}},
+		{loc(), []string{"prog", "--outptu"}, 2, []string{"--output"}},
+		{loc(), []string{"prog", "--otuptu"}, 1, nil},
+		{loc(), []string{"prog", "--dbug"}, 2, nil},
+		{loc(), []string{"prog", "--x"}, 2, nil},
+		{loc(), []string{"prog", "-x"}, 2, nil},
+		{loc(), []string{"prog", "-C"}, 2, []string{"-c"}},
+		{loc(), []string{"prog", "-O"}, 0, nil},
+		{loc(), []string{"prog", "--zzzzzzzz"}, 2, nil},
+	} {
+		reset()
+		var verbose, version, debug, color bool
+		var output string
+		FlagLong(&verbose, "verbose", 'v', "be verbose")
+		FlagLong(&version, "version", 'V', "print the version")
+		FlagLong(&debug, "debug", 0, "debug mode").Hidden()
+		FlagLong(&color, "color", 'c', "use color").Alias("colour").Negatable()
+		FlagLong(&output, "output", 'o', "output file")
+		SetSuggestDistance(tt.distance)
+		err := CommandLine.Getopt(tt.args, nil)
+		e, ok := err.(*Error)
+		if !ok || e.ErrorCode != UnknownOption {
+			t.Errorf("%s: got error %v, want an unknown option", tt.where, err)
+			continue
+		}
+		if badSlice(e.Suggestions, tt.want) {
+			t.Errorf("%s: got suggestions %q, want %q", tt.where, e.Suggestions, tt.want)
+		}
+	}
+}
+
+func TestSuggestSingleDash(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		mode  SingleDashMode
+		args  []string
+		want  []string
+	}{
+		{loc(), SingleDashStrict, []string{"prog", "-verbsoe"}, []string{"-verbose"}},
+		{loc(), SingleDashLong, []string{"prog", "--verbsoe"}, []string{"-verbose"}},
+		{loc(), SingleDashShort, []string{"prog", "--verbsoe"}, []string{"--verbose"}},
+	} {
+		s := New()
+		var verbose bool
+		s.FlagLong(&verbose, "verbose", 'v')
+		s.SetSingleDash(tt.mode)
+		err := s.Getopt(tt.args, nil)
+		e, ok := err.(*Error)
+		if !ok || e.ErrorCode != UnknownOption {
+			t.Errorf("%s: got error %v, want an unknown option", tt.where, err)
+			continue
+		}
+		if badSlice(e.Suggestions, tt.want) {
+			t.Errorf("%s: got suggestions %q, want %q", tt.where, e.Suggestions, tt.want)
+		}
+	}
+}
+
+func TestSuggestParse(t *testing.T) {
+	defer func(fn func(int)) { exit = fn }(exit)
+	exit = func(int) {}
+
+	s := New()
+	var verbose, color bool
+	s.FlagLong(&verbose, "verbose", 'v', "be verbose")
+	s.FlagLong(&color, "color", 'c', "use color").Alias("colour")
+	var b bytes.Buffer
+	s.SetOutput(&b)
+	s.SetUsage(func() {})
+	s.Parse([]string{"prog", "--verbsoe"})
+	want := "unknown option: --verbsoe\ndid you mean --verbose?\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	b.Reset()
+	s.Parse([]string{"prog", "--colr"})
+	want = "unknown option: --colr\ndid you mean --color or --colour?\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
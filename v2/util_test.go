@@ -22,6 +22,28 @@ func reset() {
 	CommandLine.args = nil
 	CommandLine.program = ""
 	CommandLine.requiredGroups = nil
+	CommandLine.permute = false
+	CommandLine.posixlyCorrect = false
+	CommandLine.abbreviations = false
+	CommandLine.negation = nil
+	CommandLine.singleDash = SingleDashShort
+	CommandLine.responseFiles = false
+	CommandLine.positionals = nil
+	CommandLine.occurrences = nil
+	CommandLine.envPrefix = ""
+	CommandLine.dynamicCompletion = false
+	CommandLine.operandCompleter = nil
+	CommandLine.sections = nil
+	CommandLine.collapseSections = false
+	CommandLine.output = nil
+	CommandLine.showAliases = false
+	CommandLine.deprecationHook = nil
+	CommandLine.suggestDistance = defaultSuggestDistance
+	CommandLine.collectErrors = false
+	CommandLine.constraints = nil
+	CommandLine.validators = nil
+	CommandLine.showRanges = false
+	CommandLine.parameters = "[parameters ...]"
 	errorString = ""
 }
 
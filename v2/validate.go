@@ -0,0 +1,306 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+)
+
+// A Validator validates the value of an option each time the option is set
+// while parsing, from the environment or from a configuration file.  The
+// validators of an option are called, in order, after the Set method of its
+// Value succeeds.  An error returned by a validator is returned as an Error
+// with the ErrorCode Invalid.  The option keeps the value it was set to.
+type Validator interface {
+	// Validate returns an error if the option o, which was just set to
+	// value, does not have a valid value.
+	Validate(o Option, value string) error
+}
+
+// A ValidatorFunc is a function used as a Validator.
+type ValidatorFunc func(o Option, value string) error
+
+// Validate returns fn(o, value).
+func (fn ValidatorFunc) Validate(o Option, value string) error {
+	return fn(o, value)
+}
+
+// Validate adds validators to the validators of o.  It is an error to add a
+// Range validator to an option that is not a number or a duration.
+func (o *option) Validate(validators ...Validator) Option {
+	for _, v := range validators {
+		if r, ok := v.(*rangeValidator); ok {
+			if _, ok := numericValue(o.value); !ok {
+				fmt.Fprintf(stderr, "%s: range %s for %s, which is not a number\n", o.where, r, o.Name())
+				exit(1)
+			}
+		}
+		o.validators = append(o.validators, v)
+	}
+	return o
+}
+
+// setValue sets o to value and then calls the validators of o.
+func (o *option) setValue(value string) error {
+	if err := o.value.Set(value, o); err != nil {
+		return err
+	}
+	return o.validate(value)
+}
+
+// validate calls the validators of o, which was just set to value.
+func (o *option) validate(value string) error {
+	for _, v := range o.validators {
+		if err := v.Validate(o, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A rangeValidator is a Validator that requires a number to be between min
+// and max, inclusive.
+type rangeValidator struct {
+	min, max reflect.Value
+}
+
+// Range returns a Validator that requires the value of an option to be at
+// least min and at most max.  The option may be any integer, floating point
+// or duration type, including Signed, Unsigned and Counter options, and min
+// and max may be any of those types, such as:
+//
+//	getopt.FlagLong(&timeout, "timeout", 't', "the timeout").Validate(getopt.Range(time.Second, time.Minute))
+//	getopt.FlagLong(&ratio, "ratio", 0, "the ratio").Validate(getopt.Range(0, 1))
+//
+// NaN is never in range.  Ranges are displayed in the help messages of
+// options if SetShowRanges is set.
+func Range(min, max interface{}) Validator {
+	r := &rangeValidator{min: reflect.ValueOf(min), max: reflect.ValueOf(max)}
+	switch {
+	case !isNumber(r.min) || !isNumber(r.max):
+		fmt.Fprintf(stderr, "invalid range %v..%v: not numbers\n", min, max)
+		exit(1)
+	case compareNumbers(r.min, r.max) > 0:
+		fmt.Fprintf(stderr, "invalid range %v..%v: min greater than max\n", min, max)
+		exit(1)
+	}
+	return r
+}
+
+// String returns r as min..max.
+func (r *rangeValidator) String() string {
+	return fmt.Sprintf("%v..%v", r.min.Interface(), r.max.Interface())
+}
+
+// Validate returns an error if the value of o is not in r.
+func (r *rangeValidator) Validate(o Option, value string) error {
+	v, _ := numericValue(o.Value())
+	switch {
+	case isNaN(v):
+		return fmt.Errorf("value out of range (%s): %s", r, value)
+	case compareNumbers(v, r.min) < 0:
+		return fmt.Errorf("value out of range (<%v): %s", r.min.Interface(), value)
+	case compareNumbers(v, r.max) > 0:
+		return fmt.Errorf("value out of range (>%v): %s", r.max.Interface(), value)
+	}
+	return nil
+}
+
+// NonEmpty returns a Validator that rejects empty values, such as --name= or
+// -n "".
+func NonEmpty() Validator {
+	return ValidatorFunc(func(o Option, value string) error {
+		if value == "" {
+			return fmt.Errorf("empty value for %s", o.Name())
+		}
+		return nil
+	})
+}
+
+// Regexp returns a Validator that requires each value to match the regular
+// expression pattern.  Use ^ and $ to match the entire value.  It is an error
+// if pattern does not compile.
+func Regexp(pattern string) Validator {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid pattern %q: %v\n", pattern, err)
+		exit(1)
+	}
+	return ValidatorFunc(func(o Option, value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("value does not match %s: %s", pattern, value)
+		}
+		return nil
+	})
+}
+
+// Finite returns a Validator that rejects NaN and infinite values of floating
+// point options.
+func Finite() Validator {
+	return ValidatorFunc(func(o Option, value string) error {
+		v, ok := numericValue(o.Value())
+		if !ok {
+			return nil
+		}
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+				return fmt.Errorf("value is not a finite number: %s", value)
+			}
+		}
+		return nil
+	})
+}
+
+// SetShowRanges calls SetShowRanges on the command line options.
+func SetShowRanges(show bool) {
+	CommandLine.SetShowRanges(show)
+}
+
+// SetShowRanges sets whether PrintOptions displays the allowed range of
+// options with a Range validator, or with Min and Max limits (see Signed and
+// Unsigned), after their help messages.
+func (s *Set) SetShowRanges(show bool) {
+	s.showRanges = show
+}
+
+// rangeHelp returns the allowed range of opt for its help message, or "" if
+// it has none.
+func rangeHelp(opt *option) string {
+	for _, v := range opt.validators {
+		if r, ok := v.(*rangeValidator); ok {
+			return "(range " + r.String() + ")"
+		}
+	}
+	switch n := opt.value.(type) {
+	case *signed:
+		signedLimitsMu.Lock()
+		l := signedLimits[n]
+		signedLimitsMu.Unlock()
+		if l != nil && (l.Min != 0 || l.Max != 0) {
+			return fmt.Sprintf("(range %d..%d)", l.Min, l.Max)
+		}
+	case *unsigned:
+		unsignedLimitsMu.Lock()
+		l := unsignedLimits[n]
+		unsignedLimitsMu.Unlock()
+		if l != nil && (l.Min != 0 || l.Max != 0) {
+			return fmt.Sprintf("(range %d..%d)", l.Min, l.Max)
+		}
+	}
+	return ""
+}
+
+// numericValue returns the number v is set to, if it is a number.
+func numericValue(v Value) (reflect.Value, bool) {
+	var p interface{} = v
+	if g := genericValue(v); g != nil {
+		p = g
+	}
+	rv := reflect.ValueOf(p)
+	if rv.Kind() != reflect.Ptr {
+		return rv, false
+	}
+	rv = rv.Elem()
+	return rv, isNumber(rv)
+}
+
+// isNumber returns true if v is an integer or floating point number.
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isNaN returns true if v is a floating point NaN.
+func isNaN(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	}
+	return false
+}
+
+// compareNumbers returns -1, 0 or 1 if the number a is less than, equal to or
+// greater than the number b, which may be of different types.
+func compareNumbers(a, b reflect.Value) int {
+	ak, bk := numberKind(a), numberKind(b)
+	switch {
+	case ak == reflect.Float64 || bk == reflect.Float64:
+		return compareFloats(toFloat(a), toFloat(b))
+	case ak == reflect.Int64 && bk == reflect.Int64:
+		return compareInts(a.Int(), b.Int())
+	case ak == reflect.Uint64 && bk == reflect.Uint64:
+		return compareUints(a.Uint(), b.Uint())
+	case ak == reflect.Int64: // b is unsigned
+		if a.Int() < 0 {
+			return -1
+		}
+		return compareUints(uint64(a.Int()), b.Uint())
+	default: // a is unsigned and b is signed
+		return -compareNumbers(b, a)
+	}
+}
+
+// numberKind returns Int64, Uint64 or Float64 for the kind of number v is.
+func numberKind(v reflect.Value) reflect.Kind {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint64
+	}
+	return reflect.Int64
+}
+
+// toFloat returns the number v as a float64.
+func toFloat(v reflect.Value) float64 {
+	switch numberKind(v) {
+	case reflect.Float64:
+		return v.Float()
+	case reflect.Uint64:
+		return float64(v.Uint())
+	}
+	return float64(v.Int())
+}
+
+// compareFloats, compareInts and compareUints return -1, 0 or 1 if a is less
+// than, equal to or greater than b.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,244 @@
+// Copyright 2017 Google Inc.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package getopt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareNumbers(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		a, b  interface{}
+		want  int
+	}{
+		{loc(), 1, 2, -1},
+		{loc(), 2, 2, 0},
+		{loc(), int8(3), int64(2), 1},
+		{loc(), uint(3), uint8(4), -1},
+		{loc(), -1, uint64(0), -1},
+		{loc(), uint64(math.MaxUint64), -1, 1},
+		{loc(), uint64(math.MaxUint64), int64(math.MaxInt64), 1},
+		{loc(), 1.5, 1, 1},
+		{loc(), float32(0.5), uint(1), -1},
+		{loc(), time.Second, time.Minute, -1},
+		{loc(), time.Second, int64(time.Second), 0},
+	} {
+		if got := compareNumbers(reflect.ValueOf(tt.a), reflect.ValueOf(tt.b)); got != tt.want {
+			t.Errorf("%s: compareNumbers(%v, %v) got %d, want %d", tt.where, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range []struct {
+		where string
+		in    []string
+		err   string
+	}{
+		{loc(), []string{"prog", "-p", "80", "-r", "0.5", "-t", "5s", "-n", "abc", "--tag=x"}, ""},
+		{loc(), []string{"prog", "-p", "1", "-p", "65535"}, ""},
+		{loc(), []string{"prog", "-p", "0"}, "value out of range (<1): 0"},
+		{loc(), []string{"prog", "--port=65536"}, "value out of range (>65535): 65536"},
+		{loc(), []string{"prog", "-r", "1.5"}, "value out of range (>1): 1.5"},
+		{loc(), []string{"prog", "-r", "NaN"}, "value is not a finite number: NaN"},
+		{loc(), []string{"prog", "-r", "-Inf"}, "value is not a finite number: -Inf"},
+		{loc(), []string{"prog", "-t", "500ms"}, "value out of range (<1s): 500ms"},
+		{loc(), []string{"prog", "-t", "2m"}, "value out of range (>1m0s): 2m"},
+		{loc(), []string{"prog", "--name="}, "empty value for --name"},
+		{loc(), []string{"prog", "--name=ABC"}, "value does not match ^[a-z]+$: ABC"},
+		{loc(), []string{"prog", "--tag=a", "--tag=b,c"}, "tags may not contain commas"},
+		{loc(), []string{"prog", "-l", "4"}, "value out of range (>3): 4"},
+		{loc(), []string{"prog", "-l", "-3"}, ""},
+	} {
+		reset()
+		var port int
+		var ratio float64
+		var timeout time.Duration
+		var name string
+		var tags []string
+		FlagLong(&port, "port", 'p', "the port").Validate(Range(1, 65535))
+		FlagLong(&ratio, "ratio", 'r', "the ratio").Validate(Finite(), Range(0, 1))
+		FlagLong(&timeout, "timeout", 't', "the timeout").Validate(Range(time.Second, time.Minute))
+		FlagLong(&name, "name", 'n', "the name").Validate(NonEmpty(), Regexp(`^[a-z]+$`))
+		FlagLong(&tags, "tag", 0, "a tag").Validate(ValidatorFunc(func(o Option, value string) error {
+			if strings.Contains(value, ",") {
+				return errors.New("tags may not contain commas")
+			}
+			return nil
+		}))
+		SignedLong("level", 'l', 0, &SignedLimit{Base: 10, Bits: 64, Min: -3, Max: 3})
+		Lookup("level").Validate(Range(-5, 5))
+		err := CommandLine.Getopt(tt.in, nil)
+		var es string
+		if err != nil {
+			es = err.Error()
+		}
+		if es != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.where, es, tt.err)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		if e, ok := err.(*Error); !ok || e.ErrorCode != Invalid {
+			t.Errorf("%s: got %#v, want an %v Error", tt.where, err, Invalid)
+		}
+	}
+}
+
+func TestValidateRangeNaN(t *testing.T) {
+	s := New()
+	var f float32
+	s.Flag(&f, 'f').Validate(Range(-1, 1))
+	if err := s.Getopt([]string{"prog", "-f", "NaN"}, nil); err == nil || err.Error() != "value out of range (-1..1): NaN" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	defer func(fn func(string) (string, bool)) { lookupEnv = fn }(lookupEnv)
+	lookupEnv = func(name string) (string, bool) {
+		if name == "PORT" {
+			return "99999", true
+		}
+		return "", false
+	}
+	s := New()
+	var port int
+	s.FlagLong(&port, "port", 'p', "the port").Env("PORT").Validate(Range(1, 65535))
+	err := s.Getopt([]string{"prog"}, nil)
+	if err == nil || err.Error() != "$PORT: value out of range (>65535): 99999" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, tt := range []struct {
+		where  string
+		config string
+		format ConfigFormat
+		err    string
+	}{
+		{loc(), `{"host": ["a", "b"]}`, ConfigJSON, ""},
+		{loc(), `{"host": ["", "BAD!"]}`, ConfigJSON, "test.conf:1: empty value for --host"},
+		{loc(), `{"host": ["a", "BAD!"]}`, ConfigJSON, "test.conf:1: value does not match ^[a-z]+$: BAD!"},
+		{loc(), "host=a\nhost=BAD!\n", ConfigINI, "test.conf:1: value does not match ^[a-z]+$: BAD!"},
+		{loc(), "host=a,BAD!\n", ConfigINI, "test.conf:1: value does not match ^[a-z]+$: a,BAD!"},
+	} {
+		s := New()
+		var hosts []string
+		s.FlagLong(&hosts, "host", 0).Validate(NonEmpty(), Regexp(`^[a-z]+$`))
+		err := s.LoadConfig(strings.NewReader(tt.config), "test.conf", tt.format)
+		var es string
+		if err != nil {
+			es = err.Error()
+		}
+		if es != tt.err {
+			t.Errorf("%s: got error %q, want %q", tt.where, es, tt.err)
+		}
+	}
+}
+
+func TestValidatePositional(t *testing.T) {
+	s := New()
+	var count int
+	s.Positional(&count, "count").Validate(Range(1, 10))
+	err := s.Getopt([]string{"prog", "11"}, nil)
+	if e, ok := err.(*Error); !ok || e.ErrorCode != Invalid || e.Error() != "value out of range (>10): 11" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestValidateDeclarationErrors(t *testing.T) {
+	defer func(w io.Writer) { stderr = w }(stderr)
+	defer func(fn func(int)) { exit = fn }(exit)
+	for _, tt := range []struct {
+		where string
+		fn    func()
+		want  string
+	}{
+		{loc(), func() { Range("a", 1) }, "invalid range a..1: not numbers\n"},
+		{loc(), func() { Range(2, 1) }, "invalid range 2..1: min greater than max\n"},
+		{loc(), func() { Regexp("(") }, "invalid pattern \"(\": error parsing regexp: missing closing ): `(`\n"},
+		{loc(), func() {
+			var name string
+			o := New().FlagLong(&name, "name", 0).(*option)
+			o.where = "file.go:1"
+			o.Validate(Range(1, 2))
+		}, "file.go:1: range 1..2 for --name, which is not a number\n"},
+	} {
+		var b bytes.Buffer
+		stderr = &b
+		exited := false
+		exit = func(int) { exited = true }
+		tt.fn()
+		if !exited {
+			t.Errorf("%s: did not exit", tt.where)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestShowRanges(t *testing.T) {
+	HelpColumn = 20
+	reset()
+	var port int
+	var ratio float64
+	var timeout time.Duration
+	var name string
+	var tags []string
+	FlagLong(&port, "port", 'p', "the port").Validate(Range(1, 65535))
+	FlagLong(&ratio, "ratio", 'r', "the ratio").Validate(Finite(), Range(0, 1))
+	FlagLong(&timeout, "timeout", 't', "the timeout").Validate(Range(time.Second, time.Minute))
+	FlagLong(&name, "name", 'n', "the name").Validate(NonEmpty(), Regexp(`^[a-z]+$`))
+	FlagLong(&tags, "tag", 0, "a tag").Validate(ValidatorFunc(func(o Option, value string) error {
+		if strings.Contains(value, ",") {
+			return errors.New("tags may not contain commas")
+		}
+		return nil
+	}))
+	SignedLong("level", 'l', 0, &SignedLimit{Base: 10, Bits: 64, Min: -3, Max: 3})
+	Lookup("level").Validate(Range(-5, 5))
+	var b bytes.Buffer
+	CommandLine.PrintOptions(&b)
+	if strings.Contains(b.String(), "range") {
+		t.Errorf("ranges displayed by default:\n%s", b.String())
+	}
+	SetShowRanges(true)
+	b.Reset()
+	CommandLine.PrintOptions(&b)
+	want := ` -l, --level=value  [0] (range -5..5)
+ -n, --name=value   the name
+ -p, --port=value   the port (range 1..65535)
+ -r, --ratio=value  the ratio (range 0..1)
+     --tag=value    a tag
+ -t, --timeout=value
+                    the timeout (range 1s..1m0s)
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Limits of Unsigned options are ranges as well.
+	s := New()
+	s.SetShowRanges(true)
+	s.UnsignedLong("count", 'c', 1, &UnsignedLimit{Base: 10, Bits: 64, Min: 1, Max: 9}, "the count")
+	b.Reset()
+	s.PrintOptions(&b)
+	want = " -c, --count=value  the count [1] (range 1..9)\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}